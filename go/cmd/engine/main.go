@@ -0,0 +1,148 @@
+// Command engine runs the matching engine on the orders read from stdin, one
+// JSON object per line, writing every order update to stdout as JSON lines.
+// Orders read without a trace ID are given one, carried by their updates
+// and logs. The book is ticked on a fixed interval, so good-till-date and
+// DAY orders expire and trading sessions open and close on time. Its metrics
+// are served in the Prometheus format on /metrics.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/medina325/stock_market/go/internal/market/metrics"
+	"github.com/medina325/stock_market/go/internal/market/replay"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// orderUpdate is an order update written to stdout.
+type orderUpdate struct {
+	OrderID       string            `json:"order_id"`
+	InvestorID    string            `json:"investor_id"`
+	AssetID       string            `json:"asset_id"`
+	Side          enums.Side        `json:"side"`
+	Status        enums.OrderStatus `json:"status"`
+	Price         float64           `json:"price"`
+	Shares        int               `json:"shares"`
+	PendingShares int               `json:"pending_shares"`
+	RejectReason  string            `json:"reject_reason,omitempty"`
+	TraceID       string            `json:"trace_id"`
+}
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to serve the metrics on")
+	buffer := flag.Int("buffer", 1024, "capacity of the order channels of the book")
+	logLevel := flag.String("log-level", "INFO", "minimum level of the logs written to stderr")
+	tick := flag.Duration("tick", time.Second, "interval between the ticks expiring orders and syncing sessions")
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		log.Fatalf("engine: invalid log level: %v", err)
+	}
+	if *tick <= 0 {
+		log.Fatalf("engine: tick interval must be positive, got %v", *tick)
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ordersIn := make(chan *entity.Order, *buffer)
+	ordersOut := make(chan *entity.Order, *buffer)
+	book := entity.NewBook(ordersIn, ordersOut, nil)
+	book.Logger = logger
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	book.Metrics = metrics.NewPrometheus(registry)
+	registry.MustRegister(metrics.NewBookCollector(book))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: *addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("engine: serving metrics: %v", err)
+		}
+	}()
+
+	go book.Trade()
+	go writeUpdates(ordersOut)
+	stopTicking := book.TickEvery(*tick)
+
+	readOrders(ordersIn)
+	log.Print("engine: all orders read, serving metrics until interrupted")
+
+	<-ctx.Done()
+	stopTicking()
+	close(ordersIn)
+	if err := server.Shutdown(context.Background()); err != nil {
+		log.Printf("engine: shutting down: %v", err)
+	}
+}
+
+// readOrders sends the orders read from stdin to the book.
+func readOrders(ordersIn chan<- *entity.Order) {
+	investors := make(map[string]*entity.Investor)
+	assets := make(map[string]*entity.Asset)
+
+	decoder := json.NewDecoder(os.Stdin)
+	for decoder.More() {
+		var record replay.OrderRecord
+		if err := decoder.Decode(&record); err != nil {
+			log.Printf("engine: reading orders: %v", err)
+			return
+		}
+
+		if investors[record.InvestorID] == nil {
+			investors[record.InvestorID] = entity.NewInvestor(record.InvestorID)
+		}
+		if assets[record.AssetID] == nil {
+			assets[record.AssetID] = entity.NewAsset(record.AssetID, record.AssetID, 0)
+		}
+		order := record.Order(investors[record.InvestorID], assets[record.AssetID])
+		if order.TraceID == "" {
+			order.TraceID = uuid.NewString()
+		}
+		ordersIn <- order
+	}
+}
+
+// writeUpdates writes the order updates of the book to stdout.
+func writeUpdates(ordersOut <-chan *entity.Order) {
+	encoder := json.NewEncoder(os.Stdout)
+	for order := range ordersOut {
+		update := orderUpdate{
+			OrderID:       order.ID,
+			InvestorID:    order.Investor.ID,
+			AssetID:       order.Asset.ID,
+			Side:          order.OrderType,
+			Status:        order.Status,
+			Price:         order.Price,
+			Shares:        order.Shares,
+			PendingShares: order.PendingShares,
+			TraceID:       order.TraceID,
+		}
+		if order.RejectReason != nil {
+			update.RejectReason = order.RejectReason.Error()
+		}
+		if err := encoder.Encode(update); err != nil {
+			log.Printf("engine: writing order update: %v", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Command loadgen runs a synthetic order flow through the matching engine and
+// reports its throughput and the latency percentiles of each action.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/loadgen"
+)
+
+func main() {
+	config := loadgen.DefaultConfig()
+	actions := flag.Int("actions", 1000000, "number of orders and cancellations to send")
+	flag.IntVar(&config.Assets, "assets", config.Assets, "number of assets")
+	flag.IntVar(&config.Investors, "investors", config.Investors, "number of investors")
+	flag.Float64Var(&config.AssetSkew, "asset-skew", config.AssetSkew, "Zipf exponent of the asset popularity, uniform up to 1")
+	flag.Float64Var(&config.CancelRatio, "cancel-ratio", config.CancelRatio, "share of the actions cancelling a previous order")
+	flag.Float64Var(&config.MidPrice, "mid-price", config.MidPrice, "price the orders are centered on")
+	flag.Float64Var(&config.TickSize, "tick-size", config.TickSize, "price increment")
+	flag.Float64Var(&config.PriceStdDev, "price-stddev", config.PriceStdDev, "standard deviation of the order prices, in ticks")
+	flag.IntVar(&config.MaxShares, "max-shares", config.MaxShares, "largest order size")
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "seed of the order flow")
+	flag.Parse()
+
+	generator, err := loadgen.NewGenerator(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ordersOut := make(chan *entity.Order, 1024)
+	go func() {
+		for range ordersOut {
+		}
+	}()
+
+	book := entity.NewBook(nil, ordersOut, nil)
+	book.IDGenerator = entity.NewSequenceIDGenerator("")
+	book.TickSize = config.TickSize
+
+	start := time.Now()
+	report := loadgen.Run(book, generator, *actions)
+	elapsed := time.Since(start)
+	close(ordersOut)
+
+	resting := 0
+	for _, assetID := range book.AssetIDs() {
+		depth := book.Depth(assetID)
+		resting += len(depth.Bids) + len(depth.Asks)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "actions\t%d\n", report.Count)
+	fmt.Fprintf(w, "elapsed\t%v\n", elapsed)
+	fmt.Fprintf(w, "throughput\t%.0f actions/s\n", float64(report.Count)/elapsed.Seconds())
+	fmt.Fprintf(w, "transactions\t%d\n", len(book.Transactions))
+	fmt.Fprintf(w, "resting price levels\t%d\n", resting)
+	fmt.Fprintf(w, "latency mean\t%v\n", report.Mean)
+	fmt.Fprintf(w, "latency min\t%v\n", report.Min)
+	fmt.Fprintf(w, "latency p50\t%v\n", report.P50)
+	fmt.Fprintf(w, "latency p90\t%v\n", report.P90)
+	fmt.Fprintf(w, "latency p99\t%v\n", report.P99)
+	fmt.Fprintf(w, "latency p99.9\t%v\n", report.P999)
+	fmt.Fprintf(w, "latency max\t%v\n", report.Max)
+	w.Flush()
+}
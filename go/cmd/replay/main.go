@@ -0,0 +1,79 @@
+// Command replay runs a file of orders, one JSON object per line, through
+// the matching engine on a simulated clock and writes the trades, as JSON
+// lines, and the final state of the book. Replaying the same file with the
+// same seed always produces byte-identical output.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/replay"
+)
+
+func main() {
+	ordersPath := flag.String("orders", "-", "file to read the orders from, - for stdin")
+	tradesPath := flag.String("trades", "-", "file to write the trades to, - for stdout")
+	statePath := flag.String("state", "", "file to write the final book state to, none when empty")
+	seed := flag.Int64("seed", 1, "seed of the transaction IDs")
+	sequenceIDs := flag.Bool("sequence-ids", false, "number the transactions sequentially instead of using seeded UUIDs")
+	start := flag.String("start", "1970-01-01T00:00:00Z", "time the simulated clock starts at, in RFC 3339")
+	flag.Parse()
+
+	startTime, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		log.Fatalf("replay: invalid start time: %v", err)
+	}
+
+	orders, err := openInput(*ordersPath)
+	if err != nil {
+		log.Fatalf("replay: %v", err)
+	}
+	defer orders.Close()
+
+	replayer := replay.NewReplayer(*seed, startTime)
+	defer replayer.Close()
+	if *sequenceIDs {
+		replayer.Book.IDGenerator = entity.NewSequenceIDGenerator("")
+	}
+
+	if err := replayer.Run(orders); err != nil {
+		log.Fatalf("replay: reading orders: %v", err)
+	}
+
+	if err := writeOutput(*tradesPath, replayer.WriteTrades); err != nil {
+		log.Fatalf("replay: writing trades: %v", err)
+	}
+	if *statePath != "" {
+		if err := writeOutput(*statePath, replayer.WriteState); err != nil {
+			log.Fatalf("replay: writing book state: %v", err)
+		}
+	}
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,199 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// Halt stops matching on an asset until Resume is called. Depending on
+// RejectWhileHalted, orders arriving in the meantime either rest in the book
+// without being matched or are rejected.
+func (b *Book) Halt(assetID string, reason string) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	delete(b.haltedUntil, assetID)
+	b.setPhase(assetID, enums.Halted, reason)
+}
+
+// Resume restarts continuous trading on a halted asset, uncrossing whatever
+// accumulated in its book during the halt with an auction.
+func (b *Book) Resume(assetID string) *AuctionResult {
+	b.mu.Lock()
+	defer b.unlock()
+
+	return b.resume(assetID, "")
+}
+
+// marketHalt holds what HaltMarket overrode, for ResumeMarket to restore.
+type marketHalt struct {
+	marketPhase enums.TradingPhase
+	phases      map[string]enums.TradingPhase
+	haltedUntil map[string]time.Time
+}
+
+// HaltMarket stops matching on every asset until ResumeMarket is called.
+func (b *Book) HaltMarket(reason string) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if b.marketHalt == nil {
+		b.marketHalt = &marketHalt{
+			marketPhase: b.marketPhase,
+			phases:      b.phases,
+			haltedUntil: b.haltedUntil,
+		}
+	}
+	b.haltedUntil = make(map[string]time.Time)
+	b.setMarketPhase(enums.Halted, reason)
+}
+
+// ResumeMarket lifts the halt of HaltMarket, putting every asset back in the
+// phase it was in before, unless its phase was set during the halt. Assets
+// halted on their own stay halted, their volatility halts running again. The
+// market goes back to continuous trading when it was not halted by
+// HaltMarket. The book of each asset back in continuous trading is uncrossed
+// with an auction, whose results are returned.
+func (b *Book) ResumeMarket() []*AuctionResult {
+	b.mu.Lock()
+	defer b.unlock()
+
+	halt := b.marketHalt
+	if halt == nil {
+		halt = &marketHalt{
+			marketPhase: enums.Continuous,
+			phases:      make(map[string]enums.TradingPhase),
+			haltedUntil: make(map[string]time.Time),
+		}
+	}
+	b.marketHalt = nil
+
+	setDuringHalt := b.phases
+	for assetID, until := range halt.haltedUntil {
+		if _, ok := setDuringHalt[assetID]; !ok {
+			b.haltedUntil[assetID] = until
+		}
+	}
+	b.marketPhase = halt.marketPhase
+	b.phases = halt.phases
+	for assetID, phase := range setDuringHalt {
+		b.phases[assetID] = phase
+	}
+
+	b.publishStatus("", b.marketPhase, "")
+	assetIDs := make([]string, 0, len(b.phases))
+	for assetID := range b.phases {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Strings(assetIDs)
+	for _, assetID := range assetIDs {
+		b.publishStatus(assetID, b.phases[assetID], "")
+	}
+
+	results := []*AuctionResult{}
+	for _, assetID := range b.assetIDs() {
+		if b.phase(assetID) == enums.Continuous {
+			results = append(results, b.uncross(assetID))
+		}
+	}
+	return results
+}
+
+// CancelAll cancels every order of an asset, the ones resting in the book
+// and the ones queued for the next session, publishing each cancellation. It
+// returns the cancelled orders.
+func (b *Book) CancelAll(assetID string) []*Order {
+	b.mu.Lock()
+	defer b.unlock()
+
+	return b.cancelOrders(assetID, func(order *Order) bool { return true })
+}
+
+// CancelOrder cancels a single order, resting in the book or queued for the
+// next session, publishing the cancellation. It reports whether the order
+// was still there to be cancelled.
+func (b *Book) CancelOrder(order *Order) bool {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if !b.orderQueue(order.Asset.ID, order.OrderType).Remove(order) && !b.unqueue(order) {
+		return false
+	}
+	b.cancel(order)
+	return true
+}
+
+// CancelOrderByID cancels the investor's order with the given ID resting in
+// the book of an asset, publishing the cancellation. It returns the cancelled
+// order, or false when no such order rests in the book.
+func (b *Book) CancelOrderByID(assetID string, investorID string, orderID string) (*Order, bool) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	for _, side := range []enums.Side{enums.Buy, enums.Sell} {
+		orders := b.restingSide(assetID, side)
+		if orders == nil {
+			continue
+		}
+		if order := orders.Find(investorID, orderID); order != nil {
+			orders.Remove(order)
+			b.cancel(order)
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+func (b *Book) resume(assetID string, reason string) *AuctionResult {
+	delete(b.haltedUntil, assetID)
+	result := b.uncross(assetID)
+	b.setPhase(assetID, enums.Continuous, reason)
+	return result
+}
+
+// cancelOrders cancels the resting and queued orders of an asset matching
+// cancel, publishing each cancellation.
+func (b *Book) cancelOrders(assetID string, cancel func(order *Order) bool) []*Order {
+	cancelled := []*Order{}
+	for _, side := range []enums.Side{enums.Buy, enums.Sell} {
+		cancelled = append(cancelled, b.orderQueue(assetID, side).RemoveIf(cancel)...)
+	}
+
+	kept := []*Order{}
+	for _, order := range b.queued[assetID] {
+		if cancel(order) {
+			cancelled = append(cancelled, order)
+		} else {
+			kept = append(kept, order)
+		}
+	}
+	b.queued[assetID] = kept
+
+	for _, order := range cancelled {
+		b.cancel(order)
+	}
+	return cancelled
+}
+
+// assetIDs returns the IDs of every asset the book has seen orders for,
+// sorted so commands walking all assets behave the same way on every run.
+func (b *Book) assetIDs() []string {
+	seen := make(map[string]bool)
+	for _, queues := range []map[string]BookSide{b.buyOrders, b.sellOrders} {
+		for assetID := range queues {
+			seen[assetID] = true
+		}
+	}
+	for assetID := range b.queued {
+		seen[assetID] = true
+	}
+
+	assetIDs := make([]string, 0, len(seen))
+	for assetID := range seen {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Strings(assetIDs)
+	return assetIDs
+}
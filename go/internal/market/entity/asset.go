@@ -5,10 +5,14 @@ package entity
 // It includes information about the asset's unique identifier (ID), name, and
 // market volume. An asset is a financial instrument or entity that can be
 // traded, such as stocks, bonds, or commodities.
+//
+// The reference price is the asset's previous closing price, which anchors
+// the price bands of the book until the asset trades.
 type Asset struct {
-	ID           string
-	Name         string
-	MarketVolume int
+	ID             string
+	Name           string
+	MarketVolume   int
+	ReferencePrice float64
 }
 
 func NewAsset(id string, name string, marketV int) *Asset {
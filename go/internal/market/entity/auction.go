@@ -0,0 +1,245 @@
+package entity
+
+import (
+	"math"
+	"sort"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// AuctionResult describes how an auction uncrosses an asset's book.
+type AuctionResult struct {
+	AssetID string
+	// Price is the single price every crossing order trades at.
+	Price float64
+	// Shares is the volume executed at Price.
+	Shares int
+	// Imbalance is the buy volume minus the sell volume willing to trade at
+	// Price. It is left resting in the book after the auction.
+	Imbalance int
+}
+
+// IndicativeAuction returns the result an auction would have if it ran now,
+// without executing anything.
+func (b *Book) IndicativeAuction(assetID string) *AuctionResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result, _ := uncrossingPrice(b.orderQueue(assetID, enums.Buy), b.orderQueue(assetID, enums.Sell), b.lastPrices[assetID])
+	result.AssetID = assetID
+	return result
+}
+
+// RunAuction uncrosses an asset's book, executing every crossing order at the
+// single price that maximizes the executed volume.
+//
+// An opening auction, or one ending a halt, moves the asset to
+// enums.Continuous trading, and a closing auction moves it from
+// enums.PreClose to enums.MarketClosed.
+func (b *Book) RunAuction(assetID string) *AuctionResult {
+	b.mu.Lock()
+	defer b.unlock()
+
+	result := b.uncross(assetID)
+
+	switch b.phase(assetID) {
+	case enums.PreOpen, enums.Halted:
+		delete(b.haltedUntil, assetID)
+		b.setPhase(assetID, enums.Continuous, "")
+	case enums.PreClose:
+		b.setPhase(assetID, enums.MarketClosed, "")
+	}
+
+	return result
+}
+
+func (b *Book) uncross(assetID string) *AuctionResult {
+	buyOrders := b.orderQueue(assetID, enums.Buy)
+	sellOrders := b.orderQueue(assetID, enums.Sell)
+
+	result, ok := uncrossingPrice(buyOrders, sellOrders, b.lastPrices[assetID])
+	result.AssetID = assetID
+	if !ok {
+		return result
+	}
+
+	executedShares := 0
+	for buyOrders.Len() > 0 && sellOrders.Len() > 0 {
+		buyOrder := buyOrders.Best()
+		sellOrder := sellOrders.Best()
+
+		if buyOrder.Price < result.Price || sellOrder.Price > result.Price {
+			break
+		}
+
+		if buyOrder.Investor.ID == sellOrder.Investor.ID {
+			b.preventAuctionSelfTrade(buyOrders, sellOrders)
+			continue
+		}
+
+		buyOrders.PopBest()
+		sellOrders.PopBest()
+
+		aggressor := enums.Buy
+		if sellOrder.sequence > buyOrder.sequence {
+			aggressor = enums.Sell
+		}
+
+		transactionShares := getTransactionShares(sellOrder.PendingShares, buyOrder.PendingShares)
+		transaction := b.newTransaction(sellOrder, buyOrder, transactionShares, result.Price, aggressor)
+		transaction.Auction = true
+		transaction.ApplyFees(b.FeeSchedule)
+		b.ExecuteTransaction(transaction)
+
+		b.publish(buyOrder)
+		b.publish(sellOrder)
+		b.releaseTransaction(transaction)
+
+		if buyOrder.PendingShares > 0 {
+			b.requeue(buyOrders, buyOrder, transactionShares)
+		}
+		if sellOrder.PendingShares > 0 {
+			b.requeue(sellOrders, sellOrder, transactionShares)
+		}
+
+		executedShares += transactionShares
+	}
+
+	result.Shares = executedShares
+	return result
+}
+
+// preventAuctionSelfTrade applies the book's self-trade prevention mode to
+// the best buy and sell orders of an auction, both belonging to the same
+// investor. The order that arrived last plays the part of the incoming order.
+func (b *Book) preventAuctionSelfTrade(buyOrders, sellOrders BookSide) {
+	newerOrders, olderOrders := buyOrders, sellOrders
+	if sellOrders.Best().sequence > buyOrders.Best().sequence {
+		newerOrders, olderOrders = sellOrders, buyOrders
+	}
+	newerOrder, olderOrder := newerOrders.Best(), olderOrders.Best()
+
+	switch b.SelfTradePrevention {
+	case enums.CancelOldest:
+		olderOrders.PopBest()
+		b.cancel(olderOrder)
+	case enums.CancelBoth:
+		olderOrders.PopBest()
+		newerOrders.PopBest()
+		b.cancel(olderOrder)
+		b.cancel(newerOrder)
+	case enums.DecrementAndCancel:
+		shares := getTransactionShares(olderOrder.PendingShares, newerOrder.PendingShares)
+		for _, queue := range []BookSide{olderOrders, newerOrders} {
+			order := queue.PopBest()
+			order.PendingShares -= shares
+			if order.PendingShares == 0 {
+				b.cancel(order)
+			} else {
+				order.VisibleShares = minInt(order.VisibleShares, order.PendingShares)
+				queue.Add(order)
+				b.audit(enums.OrderAmended, order, nil)
+				b.publish(order)
+			}
+		}
+	default:
+		newerOrders.PopBest()
+		b.cancel(newerOrder)
+	}
+}
+
+// uncrossingPrice computes the equilibrium price of an auction: the limit
+// price that maximizes the executed volume. Ties are broken by the smallest
+// imbalance, then by the market pressure (the highest price when every tied
+// price has more buy volume left, the lowest one when every tied price has
+// more sell volume left), and finally by the closest price to the reference
+// price, or to the middle of the tied prices when there is no reference.
+//
+// It reports false when the book does not cross.
+func uncrossingPrice(buyOrders, sellOrders BookSide, referencePrice float64) (*AuctionResult, bool) {
+	prices := candidatePrices(buyOrders, sellOrders)
+
+	bestShares := 0
+	tied := []AuctionResult{}
+	for _, price := range prices {
+		buyShares, sellShares := 0, 0
+		buyOrders.Each(func(order *Order) {
+			if order.Price >= price {
+				buyShares += order.PendingShares
+			}
+		})
+		sellOrders.Each(func(order *Order) {
+			if order.Price <= price {
+				sellShares += order.PendingShares
+			}
+		})
+
+		shares := getTransactionShares(sellShares, buyShares)
+		if shares == 0 || shares < bestShares {
+			continue
+		}
+
+		candidate := AuctionResult{Price: price, Shares: shares, Imbalance: buyShares - sellShares}
+		if shares > bestShares || absInt(candidate.Imbalance) < absInt(tied[0].Imbalance) {
+			bestShares = shares
+			tied = []AuctionResult{candidate}
+		} else if absInt(candidate.Imbalance) == absInt(tied[0].Imbalance) {
+			tied = append(tied, candidate)
+		}
+	}
+
+	if len(tied) == 0 {
+		return &AuctionResult{}, false
+	}
+
+	buyPressure, sellPressure := true, true
+	for _, candidate := range tied {
+		buyPressure = buyPressure && candidate.Imbalance > 0
+		sellPressure = sellPressure && candidate.Imbalance < 0
+	}
+
+	// Candidates are sorted by ascending price.
+	switch {
+	case buyPressure:
+		return &tied[len(tied)-1], true
+	case sellPressure:
+		return &tied[0], true
+	}
+
+	target := referencePrice
+	if target <= 0 {
+		target = (tied[0].Price + tied[len(tied)-1].Price) / 2
+	}
+
+	best := &tied[0]
+	for i := range tied {
+		if math.Abs(tied[i].Price-target) < math.Abs(best.Price-target) {
+			best = &tied[i]
+		}
+	}
+	return best, true
+}
+
+// candidatePrices returns every distinct limit price of the resting orders,
+// sorted in ascending order.
+func candidatePrices(buyOrders, sellOrders BookSide) []float64 {
+	seen := make(map[float64]bool)
+	prices := []float64{}
+	for _, side := range []BookSide{buyOrders, sellOrders} {
+		side.Each(func(order *Order) {
+			if !seen[order.Price] {
+				seen[order.Price] = true
+				prices = append(prices, order.Price)
+			}
+		})
+	}
+	sort.Float64s(prices)
+	return prices
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
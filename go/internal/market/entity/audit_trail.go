@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// OrderEvent is a single entry of the audit trail: something that happened
+// to an order, numbered in the sequence the book processed it in.
+type OrderEvent struct {
+	Sequence      uint64               `json:"sequence"`
+	DateTime      time.Time            `json:"date_time"`
+	Type          enums.OrderEventType `json:"type"`
+	OrderID       string               `json:"order_id"`
+	InvestorID    string               `json:"investor_id"`
+	AssetID       string               `json:"asset_id"`
+	Side          enums.Side           `json:"side"`
+	Price         float64              `json:"price"`
+	Shares        int                  `json:"shares"`
+	PendingShares int                  `json:"pending_shares"`
+	Status        enums.OrderStatus    `json:"status"`
+	TransactionID string               `json:"transaction_id,omitempty"`
+	Reason        string               `json:"reason,omitempty"`
+}
+
+var orderEventCSVHeader = []string{
+	"sequence", "date_time", "type", "order_id", "investor_id", "asset_id", "side",
+	"price", "shares", "pending_shares", "status", "transaction_id", "reason",
+}
+
+func (e OrderEvent) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(e.Sequence, 10),
+		e.DateTime.Format(time.RFC3339Nano),
+		e.Type.String(),
+		e.OrderID,
+		e.InvestorID,
+		e.AssetID,
+		e.Side.String(),
+		strconv.FormatFloat(e.Price, 'f', -1, 64),
+		strconv.Itoa(e.Shares),
+		strconv.Itoa(e.PendingShares),
+		e.Status.String(),
+		e.TransactionID,
+		e.Reason,
+	}
+}
+
+// AuditTrail is the append-only record of every order event of a book.
+type AuditTrail struct {
+	mu       sync.Mutex
+	events   []OrderEvent
+	sequence uint64
+}
+
+func NewAuditTrail() *AuditTrail {
+	return &AuditTrail{
+		events: []OrderEvent{},
+	}
+}
+
+// Record appends an event to the trail, giving it the next sequence number.
+func (a *AuditTrail) Record(event OrderEvent) OrderEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sequence++
+	event.Sequence = a.sequence
+	a.events = append(a.events, event)
+	return event
+}
+
+// Events returns a copy of every event of the trail, in sequence order.
+func (a *AuditTrail) Events() []OrderEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]OrderEvent{}, a.events...)
+}
+
+// OrderEvents returns the events of a single order, in sequence order.
+func (a *AuditTrail) OrderEvents(orderID string) []OrderEvent {
+	return a.filter(func(event OrderEvent) bool {
+		return event.OrderID == orderID
+	})
+}
+
+// Day returns the events of the trading day the given time falls on, taken
+// in its location.
+func (a *AuditTrail) Day(day time.Time) []OrderEvent {
+	date := day.Format(dateLayout)
+	return a.filter(func(event OrderEvent) bool {
+		return event.DateTime.In(day.Location()).Format(dateLayout) == date
+	})
+}
+
+func (a *AuditTrail) filter(keep func(event OrderEvent) bool) []OrderEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	events := []OrderEvent{}
+	for _, event := range a.events {
+		if keep(event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// WriteCSV exports the events of a trading day as CSV, with a header row.
+func (a *AuditTrail) WriteCSV(w io.Writer, day time.Time) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(orderEventCSVHeader); err != nil {
+		return err
+	}
+	for _, event := range a.Day(day) {
+		if err := writer.Write(event.csvRecord()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONLines exports the events of a trading day as JSON lines, one
+// event per line.
+func (a *AuditTrail) WriteJSONLines(w io.Writer, day time.Time) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range a.Day(day) {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// audit records an order event on the book's audit trail, when it has one.
+// Fills are recorded with the price and shares of their transaction.
+func (b *Book) audit(eventType enums.OrderEventType, order *Order, t *Transaction) {
+	if b.AuditTrail == nil {
+		return
+	}
+
+	event := OrderEvent{
+		DateTime:      b.Clock.Now(),
+		Type:          eventType,
+		OrderID:       order.ID,
+		InvestorID:    order.Investor.ID,
+		AssetID:       order.Asset.ID,
+		Side:          order.OrderType,
+		Price:         order.Price,
+		Shares:        order.Shares,
+		PendingShares: order.PendingShares,
+		Status:        order.Status,
+	}
+	if t != nil {
+		event.Price = t.Price
+		event.Shares = t.Shares
+		event.TransactionID = t.ID()
+	}
+	if order.RejectReason != nil {
+		event.Reason = order.RejectReason.Error()
+	}
+	b.AuditTrail.Record(event)
+}
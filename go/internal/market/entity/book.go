@@ -1,8 +1,9 @@
 package entity
 
 import (
-	"container/heap"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/medina325/stock_market/go/internal/market/enums"
 )
@@ -12,8 +13,96 @@ type Book struct {
 	Orders       []*Order
 	Transactions []*Transaction
 	OrdersChanIn chan *Order
+	// OrderChanOut receives the order updates of the book. Like those on
+	// StatusChanOut, they are sent once the book is unlocked, so whoever
+	// receives them can read the book in the meantime.
 	OrderChanOut chan *Order
 	Wg           *sync.WaitGroup
+
+	// SelfTradePrevention decides what happens when an incoming order would
+	// match a resting order of the same investor. It defaults to
+	// enums.CancelNewest.
+	SelfTradePrevention enums.SelfTradePrevention
+	// PostOnlyReprice makes the book re-price post-only orders that would
+	// cross on arrival one TickSize away from the opposite best price,
+	// instead of rejecting them.
+	PostOnlyReprice bool
+	// TickSize is the minimum price increment of the book.
+	TickSize float64
+	// FeeSchedule holds the fees charged on each transaction. A nil schedule
+	// charges no fees.
+	FeeSchedule *FeeSchedule
+	// Clock tells the book what time it is. It defaults to the wall time.
+	Clock Clock
+	// IDGenerator hands out the IDs of the book's transactions. It defaults
+	// to random UUIDs.
+	IDGenerator IDGenerator
+	// Schedules holds the trading hours of each asset, keyed by asset ID.
+	// Assets without a schedule can be traded at any time.
+	Schedules map[string]*TradingSchedule
+	// QueueOutsideHours makes the book hold orders that arrive outside the
+	// trading hours of their asset until the next session opens, instead of
+	// rejecting them.
+	QueueOutsideHours bool
+	// PriceBands holds the price band of each asset, keyed by asset ID.
+	// Assets without a band can trade at any price.
+	PriceBands map[string]*PriceBand
+	// RejectWhileHalted makes the book reject orders for halted assets,
+	// instead of resting them until trading resumes.
+	RejectWhileHalted bool
+	// StatusChanOut receives the trading phase changes of the assets, when
+	// set.
+	StatusChanOut chan *AssetStatus
+	// RiskGate checks the orders against the pre-trade limits of their
+	// investors before they reach the book, when set.
+	RiskGate *RiskGate
+	// Candles aggregates the transactions of the book into OHLCV candles,
+	// when set.
+	Candles *CandleAggregator
+	// ClearingHouse settles the transactions of the book some days after
+	// they happen, when set. Otherwise positions are settled right away.
+	ClearingHouse *ClearingHouse
+	// Ledger records the cash and securities movements of every transaction,
+	// when set.
+	Ledger *Ledger
+	// AuditTrail records every event of every order processed, when set.
+	AuditTrail *AuditTrail
+	// Metrics receives the measurements of the book, when set.
+	Metrics Metrics
+	// Logger receives the debug logs of every stage an order goes through,
+	// and the errors of the hooks that can fail. It defaults to
+	// slog.Default().
+	Logger *slog.Logger
+	// TransactionPool recycles the book's transactions, when set. See
+	// TransactionPool for what a pooled book no longer keeps.
+	TransactionPool *TransactionPool
+	// PriceLevels makes the book keep its resting orders in a
+	// PriceLevelSide instead of an OrderQueue. It must be set before the
+	// first order is processed.
+	PriceLevels bool
+
+	// mu guards the order queues, so they can be inspected while Trade is
+	// running.
+	mu          sync.Mutex
+	buyOrders   map[string]BookSide
+	sellOrders  map[string]BookSide
+	sequence    uint64
+	marketPhase enums.TradingPhase
+	phases      map[string]enums.TradingPhase
+	lastPrices  map[string]float64
+	sessionOpen map[string]bool
+	queued      map[string][]*Order
+	haltedUntil map[string]time.Time
+	blocked     map[string]map[string]bool
+	expiries    expiryQueue
+	marketHalt  *marketHalt
+
+	// outbox holds the updates published while mu is held, sent by unlock
+	// once it is released. sendMu is held while sending them, and sending
+	// holds the buffers being sent, reused once they are.
+	outbox  updates
+	sending updates
+	sendMu  sync.Mutex
 }
 
 func NewBook(orderChanIn chan *Order, orderChanOut chan *Order, wg *sync.WaitGroup) *Book {
@@ -23,6 +112,20 @@ func NewBook(orderChanIn chan *Order, orderChanOut chan *Order, wg *sync.WaitGro
 		OrdersChanIn: orderChanIn,
 		OrderChanOut: orderChanOut,
 		Wg:           wg,
+		TickSize:     0.01,
+		Clock:        SystemClock{},
+		IDGenerator:  UUIDGenerator{},
+		Logger:       slog.Default(),
+		Schedules:    make(map[string]*TradingSchedule),
+		PriceBands:   make(map[string]*PriceBand),
+		buyOrders:    make(map[string]BookSide),
+		sellOrders:   make(map[string]BookSide),
+		phases:       make(map[string]enums.TradingPhase),
+		lastPrices:   make(map[string]float64),
+		sessionOpen:  make(map[string]bool),
+		queued:       make(map[string][]*Order),
+		haltedUntil:  make(map[string]time.Time),
+		blocked:      make(map[string]map[string]bool),
 	}
 }
 
@@ -38,104 +141,469 @@ func getTransactionShares(sellingShares, buyingShares int) int {
 	return buyingShares
 }
 
-func addOrderQueueToAssetMap(mapping *map[string]*OrderQueue, assetID string) {
-	if (*mapping)[assetID] == nil {
-		(*mapping)[assetID] = NewOrderQueue()
-		heap.Init((*mapping)[assetID])
+// orderQueue returns the side of an asset's book holding the resting orders
+// of the given side, creating it on first use.
+func (b *Book) orderQueue(assetID string, side enums.Side) BookSide {
+	if orders := b.restingSide(assetID, side); orders != nil {
+		return orders
+	}
+
+	var orders BookSide = NewOrderQueue()
+	if b.PriceLevels {
+		orders = NewPriceLevelSide(side)
+	}
+	if side == enums.Buy {
+		b.buyOrders[assetID] = orders
+	} else {
+		b.sellOrders[assetID] = orders
 	}
+	return orders
 }
 
-func (b *Book) Trade() {
-	buyOrders := make(map[string]*OrderQueue)
-	sellOrders := make(map[string]*OrderQueue)
+// restingSide returns the side of an asset's book holding the resting orders
+// of the given side, or nil when the book has none yet.
+func (b *Book) restingSide(assetID string, side enums.Side) BookSide {
+	if side == enums.Buy {
+		return b.buyOrders[assetID]
+	}
+	return b.sellOrders[assetID]
+}
 
+// crosses reports whether an incoming order is willing to trade at the price
+// of a resting order.
+func crosses(order, restingOrder *Order) bool {
+	if order.OrderType == enums.Buy {
+		return restingOrder.Price <= order.Price
+	}
+	return restingOrder.Price >= order.Price
+}
+
+func (b *Book) Trade() {
 	for order := range b.OrdersChanIn {
-		assetID := order.Asset.ID
+		b.Process(order)
+	}
+}
 
-		addOrderQueueToAssetMap(&buyOrders, assetID)
-		addOrderQueueToAssetMap(&sellOrders, assetID)
+// Process handles a single incoming order synchronously. During continuous
+// trading the order is matched against the opposite side of its asset's book
+// in price-time priority: best price first and, at the same price, first
+// come first served. It sweeps every price level it crosses, and only then
+// does whatever is left of it rest in the book. During an auction call phase
+// it rests without being matched.
+func (b *Book) Process(order *Order) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if b.Metrics != nil {
+		start := time.Now()
+		b.Metrics.OrderReceived(order)
+		defer func() { b.Metrics.OrderProcessed(order, time.Since(start)) }()
+	}
 
-		if order.OrderType == enums.Buy {
-			buyOrders[assetID].Push(order)
+	now := b.Clock.Now()
 
-			thereAreNoSellOrders := sellOrders[assetID].Len() == 0
+	// Orders that expired since the last tick must not trade with this one.
+	b.expireGoodTillDateOrders(now)
 
-			if thereAreNoSellOrders {
-				continue
-			}
+	b.audit(enums.OrderReceived, order, nil)
+	b.logOrder("order received", order)
+	b.syncSession(order.Asset.ID, now)
 
-			thereAreNoSellOrderMatch := (*sellOrders[assetID])[0].Price > order.Price
-			orderMatchHasNoPendingShares := (*sellOrders[assetID])[0].PendingShares == 0
+	if b.isBlocked(order) {
+		b.reject(order, ErrInvestorBlocked)
+		return
+	}
 
-			if thereAreNoSellOrderMatch || orderMatchHasNoPendingShares {
-				continue
-			}
+	if order.IsExpired(now) {
+		order.Status = enums.Expired
+		b.publish(order)
+		return
+	}
 
-			sellOrder := sellOrders[assetID].Pop().(*Order)
+	if b.RiskGate != nil {
+		if err := b.RiskGate.Check(order); err != nil {
+			b.reject(order, err)
+			return
+		}
+	}
 
-			transactionShares := getTransactionShares(sellOrder.PendingShares, order.PendingShares)
+	// The arrival number breaks expiry ties, so it is set before the order
+	// goes on the expiry heap.
+	b.stamp(order)
+	b.trackExpiry(order)
+	b.process(order)
+}
 
-			// Duvidas:
-			// - como eu sei se passo o preço da order de compra ou venda
-			transaction := NewTransaction(sellOrder, order, transactionShares, sellOrder.Price)
-			b.ExecuteTransaction(transaction)
+// process routes a stamped order by trading phase. Orders queued outside
+// trading hours come back through it at the open, keeping their arrival
+// number.
+func (b *Book) process(order *Order) {
+	assetID := order.Asset.ID
+
+	if b.Schedules[assetID] != nil && !b.sessionOpen[assetID] {
+		if b.QueueOutsideHours {
+			b.logOrder("order queued outside trading hours", order)
+			b.queued[assetID] = append(b.queued[assetID], order)
+			return
+		}
+		b.reject(order, ErrOutsideTradingHours)
+		return
+	}
 
-			sellOrder.AddTransaction(transaction)
-			order.AddTransaction(transaction)
+	if !b.checkPriceBand(order) {
+		b.reject(order, ErrPriceOutsideBand)
+		return
+	}
 
-			b.OrderChanOut <- sellOrder
-			b.OrderChanOut <- order
+	switch phase := b.phase(assetID); {
+	case phase == enums.MarketClosed:
+		b.reject(order, ErrMarketClosed)
+		return
+	case phase == enums.Halted && b.RejectWhileHalted:
+		b.reject(order, ErrAssetHalted)
+		return
+	case phase.IsCall():
+		b.audit(enums.OrderAccepted, order, nil)
+		b.logOrder("order accepted", order, slog.String("phase", phase.String()))
+		b.rest(order)
+		return
+	}
 
-			// REFACTOR
-			if sellOrder.PendingShares > 0 {
-				sellOrders[assetID].Push(sellOrder)
-			}
-		} else if order.OrderType == enums.Sell {
-			sellOrders[assetID].Push(order)
+	restingOrders := b.orderQueue(assetID, order.OrderType.Opposite())
 
-			thereAreNoBuyOrder := buyOrders[assetID].Len() == 0
+	if order.PostOnly && !b.preparePostOnly(order, restingOrders) {
+		return
+	}
 
-			if thereAreNoBuyOrder {
-				continue
-			}
+	b.audit(enums.OrderAccepted, order, nil)
+	b.logOrder("order accepted", order, slog.String("phase", enums.Continuous.String()))
+	b.match(order, restingOrders)
 
-			thereAreNoBuyOrderMatch := order.Price > (*buyOrders[assetID])[0].Price
-			orderMatchHasNoPendingShares := (*buyOrders[assetID])[0].PendingShares == 0
+	if order.Status == enums.Open && order.PendingShares > 0 {
+		b.rest(order)
+	}
+}
 
-			if thereAreNoBuyOrderMatch || orderMatchHasNoPendingShares {
-				continue
-			}
+// rest adds an order to its side of the book.
+func (b *Book) rest(order *Order) {
+	b.logOrder("order resting", order)
+	order.replenish()
+	b.orderQueue(order.Asset.ID, order.OrderType).Add(order)
+}
 
-			buyOrder := buyOrders[assetID].Pop().(*Order)
+// preparePostOnly makes sure a post-only order won't take liquidity, either
+// re-pricing it or rejecting it when it would cross the best resting order.
+// A re-priced order is rejected when its new price falls outside the price
+// band. It reports whether the order can go on to the book.
+func (b *Book) preparePostOnly(order *Order, restingOrders BookSide) bool {
+	if restingOrders.Len() == 0 || !crosses(order, restingOrders.Best()) {
+		return true
+	}
 
-			transactionShares := getTransactionShares(order.PendingShares, buyOrder.PendingShares)
-			transaction := NewTransaction(order, buyOrder, transactionShares, buyOrder.Price)
+	if !b.PostOnlyReprice || b.TickSize <= 0 {
+		b.reject(order, ErrPostOnlyWouldCross)
+		return false
+	}
 
-			b.ExecuteTransaction(transaction)
+	bestPrice := restingOrders.Best().Price
+	if order.OrderType == enums.Buy {
+		order.Price = bestPrice - b.TickSize
+	} else {
+		order.Price = bestPrice + b.TickSize
+	}
+
+	// The price band was checked against the price the order came with.
+	if !b.checkPriceBand(order) {
+		b.reject(order, ErrPriceOutsideBand)
+		return false
+	}
+	return true
+}
+
+// stamp gives an order the next arrival number, sending it to the back of
+// the time queue of its price.
+func (b *Book) stamp(order *Order) {
+	b.sequence++
+	order.sequence = b.sequence
+}
 
-			buyOrder.AddTransaction(transaction)
-			order.AddTransaction(transaction)
+// match executes the incoming order against the best resting orders for as
+// long as their prices cross. Resting orders are always traded at their own
+// price.
+func (b *Book) match(order *Order, restingOrders BookSide) {
+	for order.PendingShares > 0 && restingOrders.Len() > 0 {
+		restingOrder := restingOrders.Best()
 
-			b.OrderChanOut <- buyOrder
-			b.OrderChanOut <- order
+		if !crosses(order, restingOrder) {
+			return
+		}
 
-			if buyOrder.PendingShares > 0 {
-				buyOrders[assetID].Push(buyOrder)
+		if restingOrder.Investor.ID == order.Investor.ID {
+			if !b.preventSelfTrade(order, restingOrders) {
+				return
 			}
+			continue
+		}
+
+		if b.tripsCircuitBreaker(order.Asset, restingOrder.Price) {
+			return
+		}
+
+		restingOrders.PopBest()
+
+		sellOrder, buyOrder := restingOrder, order
+		if order.OrderType == enums.Sell {
+			sellOrder, buyOrder = order, restingOrder
+		}
+
+		transactionShares := getTransactionShares(restingOrder.matchableShares(), order.PendingShares)
+		transaction := b.newTransaction(sellOrder, buyOrder, transactionShares, restingOrder.Price, order.OrderType)
+		transaction.ApplyFees(b.FeeSchedule)
+		b.ExecuteTransaction(transaction)
+
+		b.publish(restingOrder)
+		b.publish(order)
+		b.releaseTransaction(transaction)
+
+		if restingOrder.PendingShares > 0 {
+			b.requeue(restingOrders, restingOrder, transactionShares)
+		}
+	}
+}
+
+// requeue puts a partially filled resting order back in its queue. Once an
+// iceberg order has its displayed slice consumed, a new slice is shown at the
+// back of the time queue of its price.
+func (b *Book) requeue(restingOrders BookSide, restingOrder *Order, tradedShares int) {
+	if restingOrder.IsIceberg() {
+		restingOrder.VisibleShares -= tradedShares
+		if restingOrder.VisibleShares <= 0 {
+			restingOrder.replenish()
+			// The new arrival number would move the order within the expiry
+			// heap, so it leaves the heap while being restamped.
+			b.untrackExpiry(restingOrder)
+			b.stamp(restingOrder)
+			b.trackExpiry(restingOrder)
+		}
+	}
+	restingOrders.Add(restingOrder)
+}
+
+// preventSelfTrade applies the book's self-trade prevention mode to an
+// incoming order that crosses the resting order on top of restingOrders, both
+// belonging to the same investor. It reports whether the incoming order may
+// keep matching.
+func (b *Book) preventSelfTrade(order *Order, restingOrders BookSide) bool {
+	restingOrder := restingOrders.Best()
+
+	switch b.SelfTradePrevention {
+	case enums.CancelOldest:
+		restingOrders.PopBest()
+		b.cancel(restingOrder)
+		return true
+	case enums.CancelBoth:
+		restingOrders.PopBest()
+		b.cancel(restingOrder)
+		b.cancel(order)
+		return false
+	case enums.DecrementAndCancel:
+		// The resting order leaves its side while its shares change, so the
+		// side can keep count of the shares it displays.
+		shares := getTransactionShares(restingOrder.PendingShares, order.PendingShares)
+		restingOrders.PopBest()
+		restingOrder.PendingShares -= shares
+		order.PendingShares -= shares
+
+		if restingOrder.PendingShares == 0 {
+			b.cancel(restingOrder)
+		} else {
+			restingOrder.VisibleShares = minInt(restingOrder.VisibleShares, restingOrder.PendingShares)
+			restingOrders.Add(restingOrder)
+			b.audit(enums.OrderAmended, restingOrder, nil)
+			b.publish(restingOrder)
+		}
+
+		if order.PendingShares == 0 {
+			b.cancel(order)
+			return false
 		}
+		b.audit(enums.OrderAmended, order, nil)
+		b.publish(order)
+		return true
+	default:
+		b.cancel(order)
+		return false
 	}
 }
 
+// updates holds the order and status updates published by the book while
+// its lock is held.
+type updates struct {
+	orders   []*Order
+	statuses []*AssetStatus
+}
+
+// unlock releases the book's lock, then sends the updates published while it
+// was held. Consumers of OrderChanOut and StatusChanOut can read the book
+// while they wait to be received; the next caller with updates of its own
+// takes sendMu before releasing the lock, so updates go out in the order
+// they were published.
+func (b *Book) unlock() {
+	if len(b.outbox.orders) == 0 && len(b.outbox.statuses) == 0 {
+		b.mu.Unlock()
+		return
+	}
+
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+	pending := b.outbox
+	b.outbox = updates{orders: b.sending.orders[:0], statuses: b.sending.statuses[:0]}
+	b.sending = pending
+	b.mu.Unlock()
+
+	for i, order := range pending.orders {
+		b.OrderChanOut <- order
+		pending.orders[i] = nil
+	}
+	for i, status := range pending.statuses {
+		b.StatusChanOut <- status
+		pending.statuses[i] = nil
+	}
+}
+
+// publish queues an order update for OrderChanOut, sent once the book's lock
+// is released. Orders that are no longer open stop counting against the
+// limits of their investor.
+func (b *Book) publish(order *Order) {
+	switch order.Status {
+	case enums.Rejected:
+		b.audit(enums.OrderRejected, order, nil)
+	case enums.Cancelled:
+		b.audit(enums.OrderCancelled, order, nil)
+	case enums.Expired:
+		b.audit(enums.OrderExpired, order, nil)
+	}
+
+	b.logOrder("order update published", order)
+
+	if order.Status != enums.Open {
+		b.untrackExpiry(order)
+		if b.RiskGate != nil {
+			b.RiskGate.Release(order)
+		}
+	}
+	b.outbox.orders = append(b.outbox.orders, order)
+}
+
+// reject marks an order as rejected for the given reason and publishes it.
+func (b *Book) reject(order *Order, reason error) {
+	order.Status = enums.Rejected
+	order.RejectReason = reason
+	if b.Metrics != nil {
+		b.Metrics.OrderRejected(order)
+	}
+	b.publish(order)
+}
+
+// cancel marks an order as cancelled and publishes it.
+func (b *Book) cancel(order *Order) {
+	order.Status = enums.Cancelled
+	b.publish(order)
+}
+
+// keepTransaction records a transaction in the book and on both of its
+// orders, unless it is pooled.
+func (b *Book) keepTransaction(t *Transaction) {
+	if b.TransactionPool == nil {
+		b.Transactions = append(b.Transactions, t)
+		t.SellingOrder.AddTransaction(t)
+		t.BuyingOrder.AddTransaction(t)
+	}
+}
+
+// releaseTransaction gives a transaction the book is done with back to its
+// pool, if any.
+func (b *Book) releaseTransaction(t *Transaction) {
+	if b.TransactionPool != nil {
+		b.TransactionPool.put(t)
+	}
+}
+
+// newTransaction creates a transaction with the next ID of the book's
+// generator, dated by the book's clock and traced to the aggressor order.
+// A SequenceIDGenerator only numbers the transaction, leaving its ID to be
+// formatted by Transaction.ID.
+func (b *Book) newTransaction(sellingOrder *Order, buyingOrder *Order, shares int, price float64, aggressor enums.Side) *Transaction {
+	var t *Transaction
+	if b.TransactionPool != nil {
+		t = b.TransactionPool.get()
+	} else {
+		t = &Transaction{}
+	}
+	if generator, ok := b.IDGenerator.(*SequenceIDGenerator); ok {
+		t.init("", sellingOrder, buyingOrder, shares, price, aggressor, b.Clock.Now())
+		t.Sequence, t.idPrefix = generator.next(), generator.Prefix
+	} else {
+		t.init(b.IDGenerator.NewID(), sellingOrder, buyingOrder, shares, price, aggressor, b.Clock.Now())
+	}
+	if aggressor == enums.Buy {
+		t.TraceID = buyingOrder.TraceID
+	} else {
+		t.TraceID = sellingOrder.TraceID
+	}
+	return t
+}
+
+// ExecuteTransaction settles a transaction between two orders and records
+// it. When the book has a Wg, every transaction calls Done once, after it is
+// recorded on both orders. An incoming order executes one transaction per
+// resting order it trades with, possibly across several price levels, so
+// callers waiting on Wg add one per expected transaction.
 func (b *Book) ExecuteTransaction(t *Transaction) {
-	defer b.Wg.Done()
+	if b.Wg != nil {
+		defer b.Wg.Done()
+	}
+
+	if b.ClearingHouse != nil {
+		b.ClearingHouse.Clear(t)
+	} else {
+		t.UpdateSellOrderAssetPosition()
+		t.UpdateBuyOrderAssetPosition()
+	}
 
-	t.UpdateSellOrderAssetPosition()
 	t.LiquidateSellPendingShares()
 	t.UpdateSellOrderStatus()
 
-	t.UpdateBuyOrderAssetPosition()
 	t.LiquidateBuyPendingShares()
 	t.UpdateBuyOrderStatus()
 
-	b.Transactions = append(b.Transactions, t)
+	b.audit(enums.OrderFilled, t.SellingOrder, t)
+	b.audit(enums.OrderFilled, t.BuyingOrder, t)
+
+	if b.RiskGate != nil {
+		b.RiskGate.AddTransaction(t)
+	}
+
+	if b.Ledger != nil {
+		if err := b.Ledger.PostTransaction(t); err != nil {
+			b.Logger.Error("transaction not posted to the ledger",
+				slog.String("transaction_id", t.ID()),
+				slog.String("trace_id", t.TraceID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	if b.Metrics != nil {
+		b.Metrics.TransactionExecuted(t)
+	}
+
+	if b.Candles != nil {
+		b.Candles.AddTransaction(t)
+	}
+
+	b.logTransaction(t)
+
+	b.lastPrices[t.SellingOrder.Asset.ID] = t.Price
+	b.keepTransaction(t)
 }
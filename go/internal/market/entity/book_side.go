@@ -0,0 +1,36 @@
+package entity
+
+// BookSide holds the orders resting on one side of an asset's book. The best
+// priced order comes first (highest price for buy orders, lowest price for
+// sell orders) and orders at the same price keep their arrival order. Order
+// IDs are only unique per investor, so orders are found by investor and ID;
+// an investor must not rest two orders with the same ID on a side, as the
+// RiskGate makes sure of. The shares an order displays must not change while
+// it rests: the book takes orders off their side to trade or amend them.
+type BookSide interface {
+	// Len returns how many orders rest on the side.
+	Len() int
+	// Best returns the order to be matched first, or nil when the side is
+	// empty.
+	Best() *Order
+	// Add rests an order on the side.
+	Add(order *Order)
+	// PopBest removes and returns the order to be matched first, or nil when
+	// the side is empty.
+	PopBest() *Order
+	// Remove takes an order off the side, reporting whether it was there.
+	Remove(order *Order) bool
+	// RemoveIf takes every order matching remove off the side, returning
+	// them in priority order.
+	RemoveIf(remove func(order *Order) bool) []*Order
+	// Find returns the investor's resting order with the given ID, or nil.
+	Find(investorID string, orderID string) *Order
+	// Orders returns the resting orders in priority order.
+	Orders() []*Order
+	// Depth returns the shares displayed on the side aggregated by price,
+	// in priority order, leaving out prices displaying none.
+	Depth() []PriceLevel
+	// Each calls fn for every resting order, in no particular order. It is
+	// the cheapest way through the side when the order doesn't matter.
+	Each(fn func(order *Order))
+}
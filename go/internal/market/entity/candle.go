@@ -0,0 +1,170 @@
+package entity
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCandleIntervals are the intervals charts are usually drawn with.
+var DefaultCandleIntervals = []time.Duration{
+	time.Second,
+	time.Minute,
+	5 * time.Minute,
+	time.Hour,
+	24 * time.Hour,
+}
+
+// Candle summarizes the transactions of an asset within an interval starting
+// at Start. Intervals dividing a day are aligned to midnight UTC, so daily
+// candles start at midnight UTC and hourly candles on the hour.
+type Candle struct {
+	AssetID  string
+	Interval time.Duration
+	Start    time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Volume   int
+	Notional float64
+	VWAP     float64
+	Trades   int
+
+	openTime  time.Time
+	closeTime time.Time
+}
+
+func (c *Candle) add(t *Transaction) {
+	if c.Trades == 0 {
+		c.High, c.Low = t.Price, t.Price
+	}
+	if c.Trades == 0 || t.DateTime.Before(c.openTime) {
+		c.Open, c.openTime = t.Price, t.DateTime
+	}
+	if c.Trades == 0 || !t.DateTime.Before(c.closeTime) {
+		c.Close, c.closeTime = t.Price, t.DateTime
+	}
+	if t.Price > c.High {
+		c.High = t.Price
+	}
+	if t.Price < c.Low {
+		c.Low = t.Price
+	}
+	c.Volume += t.Shares
+	c.Notional += t.Total
+	c.VWAP = c.Notional / float64(c.Volume)
+	c.Trades++
+}
+
+type candleSeriesKey struct {
+	assetID  string
+	interval time.Duration
+}
+
+// CandleAggregator builds OHLCV candles out of the transactions of each asset,
+// for every one of its intervals.
+type CandleAggregator struct {
+	mu        sync.Mutex
+	intervals []time.Duration
+	series    map[candleSeriesKey][]*Candle
+}
+
+func NewCandleAggregator(intervals ...time.Duration) *CandleAggregator {
+	if len(intervals) == 0 {
+		intervals = DefaultCandleIntervals
+	}
+	return &CandleAggregator{
+		intervals: intervals,
+		series:    map[candleSeriesKey][]*Candle{},
+	}
+}
+
+// Consume adds every transaction received on transactions until the channel
+// is closed.
+func (a *CandleAggregator) Consume(transactions <-chan *Transaction) {
+	for t := range transactions {
+		a.AddTransaction(t)
+	}
+}
+
+// AddTransaction accounts a transaction in the candles it falls into.
+// Transactions may arrive out of order.
+func (a *CandleAggregator) AddTransaction(t *Transaction) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	assetID := t.SellingOrder.Asset.ID
+	for _, interval := range a.intervals {
+		a.candle(assetID, interval, t.DateTime.Truncate(interval)).add(t)
+	}
+}
+
+// Bars returns copies of the candles of an asset for an interval, starting
+// within [from, to). With fillGaps, intervals without transactions after the
+// first candle, and before the last one, are returned as flat candles at the
+// previous close, with no volume.
+func (a *CandleAggregator) Bars(assetID string, interval time.Duration, from time.Time, to time.Time, fillGaps bool) []Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	series := a.series[candleSeriesKey{assetID, interval}]
+	bars := []Candle{}
+
+	var previous *Candle
+	for _, candle := range series {
+		if fillGaps && previous != nil {
+			for start := previous.Start.Add(interval); start.Before(candle.Start) && start.Before(to); start = start.Add(interval) {
+				if !start.Before(from) {
+					bars = append(bars, flatCandle(previous, start))
+				}
+			}
+		}
+
+		if !candle.Start.Before(to) {
+			break
+		}
+
+		if !candle.Start.Before(from) {
+			bars = append(bars, *candle)
+		}
+		previous = candle
+	}
+
+	return bars
+}
+
+// candle returns the candle of a series starting at start, creating it in
+// its place when needed.
+func (a *CandleAggregator) candle(assetID string, interval time.Duration, start time.Time) *Candle {
+	key := candleSeriesKey{assetID, interval}
+	series := a.series[key]
+
+	i := sort.Search(len(series), func(i int) bool {
+		return !series[i].Start.Before(start)
+	})
+	if i < len(series) && series[i].Start.Equal(start) {
+		return series[i]
+	}
+
+	candle := &Candle{AssetID: assetID, Interval: interval, Start: start}
+	series = append(series, nil)
+	copy(series[i+1:], series[i:])
+	series[i] = candle
+	a.series[key] = series
+
+	return candle
+}
+
+func flatCandle(previous *Candle, start time.Time) Candle {
+	return Candle{
+		AssetID:  previous.AssetID,
+		Interval: previous.Interval,
+		Start:    start,
+		Open:     previous.Close,
+		High:     previous.Close,
+		Low:      previous.Close,
+		Close:    previous.Close,
+		VWAP:     previous.Close,
+	}
+}
@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// SettlementObligation is what an investor has to settle in an asset for the
+// trades of a day, netted: Shares is positive when the investor receives
+// shares and negative when it delivers them, and Amount is positive when the
+// investor receives cash and negative when it pays.
+type SettlementObligation struct {
+	Investor       *Investor
+	AssetID        string
+	TradeDate      time.Time
+	SettlementDate time.Time
+	Shares         int
+	Amount         float64
+	Status         enums.SettlementStatus
+	FailReason     error
+}
+
+type obligationKey struct {
+	investorID string
+	assetID    string
+	tradeDate  string
+}
+
+// ClearingHouse turns transactions into settlement obligations, netted per
+// investor, asset and trade date, which settle SettlementDays business days
+// after the trade date.
+//
+// Business days are weekdays in UTC, unless a Calendar is set, in which case
+// they are the days the calendar holds a trading session in its time zone.
+type ClearingHouse struct {
+	SettlementDays int
+	Calendar       *TradingSchedule
+
+	mu          sync.Mutex
+	obligations []*SettlementObligation
+	byKey       map[obligationKey]*SettlementObligation
+}
+
+func NewClearingHouse(settlementDays int) *ClearingHouse {
+	return &ClearingHouse{
+		SettlementDays: settlementDays,
+		obligations:    []*SettlementObligation{},
+		byKey:          map[obligationKey]*SettlementObligation{},
+	}
+}
+
+// Clear records the obligations created by a transaction for the buyer and
+// the seller, holding the traded shares as unsettled in their positions.
+func (c *ClearingHouse) Clear(t *Transaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	assetID := t.SellingOrder.Asset.ID
+	tradeDate := c.date(t.DateTime)
+
+	buyer := c.obligation(t.BuyingOrder.Investor, assetID, tradeDate)
+	buyer.Shares += t.Shares
+	buyer.Amount -= t.BuyerNet
+	t.BuyingOrder.Investor.ApplyUnsettledFill(assetID, t.Shares, t.Price)
+
+	seller := c.obligation(t.SellingOrder.Investor, assetID, tradeDate)
+	seller.Shares -= t.Shares
+	seller.Amount += t.SellerNet
+	t.SellingOrder.Investor.ApplyUnsettledFill(assetID, -t.Shares, t.Price)
+}
+
+// Obligations returns every obligation recorded so far, in the order they
+// were created.
+func (c *ClearingHouse) Obligations() []*SettlementObligation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*SettlementObligation{}, c.obligations...)
+}
+
+// Settle runs the settlement of every pending or failed obligation due on or
+// before the day of date, returning them.
+//
+// Receipts are settled before deliveries, so shares received on a day can be
+// delivered on that same day. A delivery fails, and is retried on the next
+// run, when the investor does not hold enough settled shares.
+func (c *ClearingHouse) Settle(date time.Time) []*SettlementObligation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	day := c.date(date)
+	due := []*SettlementObligation{}
+	for _, obligation := range c.obligations {
+		if obligation.Status != enums.Settled && !obligation.SettlementDate.After(day) {
+			due = append(due, obligation)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Shares > 0 && due[j].Shares <= 0
+	})
+
+	for _, obligation := range due {
+		if obligation.Shares < 0 && !hasSettledShares(obligation.Investor, obligation.AssetID, -obligation.Shares) {
+			obligation.Status = enums.SettlementFailed
+			obligation.FailReason = ErrInsufficientHoldings
+			continue
+		}
+
+		obligation.Investor.SettleAssetPosition(obligation.AssetID, obligation.Shares)
+		obligation.Status = enums.Settled
+		obligation.FailReason = nil
+	}
+
+	return due
+}
+
+func (c *ClearingHouse) obligation(investor *Investor, assetID string, tradeDate time.Time) *SettlementObligation {
+	key := obligationKey{investor.ID, assetID, tradeDate.Format(dateLayout)}
+	if obligation, ok := c.byKey[key]; ok {
+		return obligation
+	}
+
+	obligation := &SettlementObligation{
+		Investor:       investor,
+		AssetID:        assetID,
+		TradeDate:      tradeDate,
+		SettlementDate: c.settlementDate(tradeDate),
+		Status:         enums.SettlementPending,
+	}
+	c.byKey[key] = obligation
+	c.obligations = append(c.obligations, obligation)
+	return obligation
+}
+
+// settlementDate returns the business day SettlementDays after a trade date.
+func (c *ClearingHouse) settlementDate(tradeDate time.Time) time.Time {
+	date := tradeDate
+	for days := 0; days < c.SettlementDays; {
+		date = date.AddDate(0, 0, 1)
+		if c.isBusinessDay(date) {
+			days++
+		}
+	}
+	return date
+}
+
+func (c *ClearingHouse) isBusinessDay(date time.Time) bool {
+	if c.Calendar != nil {
+		_, _, ok := c.Calendar.Session(date)
+		return ok
+	}
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+// date returns the midnight starting the day of t, in the time zone of the
+// calendar.
+func (c *ClearingHouse) date(t time.Time) time.Time {
+	location := time.UTC
+	if c.Calendar != nil {
+		location = c.Calendar.Location
+	}
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+}
+
+func hasSettledShares(investor *Investor, assetID string, shares int) bool {
+	assetPosition := investor.GetAssetPosition(assetID)
+	return assetPosition != nil && assetPosition.Shares >= shares
+}
@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// PriceLevel aggregates the displayed shares resting at a single price.
+type PriceLevel struct {
+	Price  float64
+	Shares int
+	Orders int
+}
+
+// SideTotals counts the orders resting on one side of an asset's book and
+// their pending shares, hidden ones included.
+type SideTotals struct {
+	AssetID string
+	Side    enums.Side
+	Orders  int
+	Shares  int
+}
+
+// BookDepth is a snapshot of the displayed price levels of an asset's book.
+// Bids are sorted from the highest price down and asks from the lowest price
+// up.
+type BookDepth struct {
+	AssetID string
+	Bids    []PriceLevel
+	Asks    []PriceLevel
+}
+
+// Depth returns the displayed price levels of an asset's book. Only the
+// visible slice of iceberg orders is accounted for, and hidden orders are
+// left out.
+func (b *Book) Depth(assetID string) *BookDepth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &BookDepth{
+		AssetID: assetID,
+		Bids:    sideDepth(b.restingSide(assetID, enums.Buy)),
+		Asks:    sideDepth(b.restingSide(assetID, enums.Sell)),
+	}
+}
+
+// sideDepth returns the displayed price levels of a side of the book, which
+// may not exist yet.
+func sideDepth(orders BookSide) []PriceLevel {
+	if orders == nil {
+		return []PriceLevel{}
+	}
+	return orders.Depth()
+}
+
+// RestingOrders returns the orders resting on one side of an asset's book,
+// hidden ones included, in the priority order they would be matched in.
+func (b *Book) RestingOrders(assetID string, side enums.Side) []*Order {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	orders := b.restingSide(assetID, side)
+	if orders == nil {
+		return []*Order{}
+	}
+	return orders.Orders()
+}
+
+// RestingTotals returns the totals of both sides of the book of every asset
+// the book has seen orders for, sorted by asset, buy side first.
+func (b *Book) RestingTotals() []SideTotals {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	totals := []SideTotals{}
+	for _, assetID := range b.assetIDs() {
+		for _, side := range []enums.Side{enums.Buy, enums.Sell} {
+			sideTotals := SideTotals{AssetID: assetID, Side: side}
+			if orders := b.restingSide(assetID, side); orders != nil {
+				orders.Each(func(order *Order) {
+					sideTotals.Orders++
+					sideTotals.Shares += order.PendingShares
+				})
+			}
+			totals = append(totals, sideTotals)
+		}
+	}
+	return totals
+}
+
+// AssetIDs returns the IDs of every asset the book has seen orders for,
+// sorted.
+func (b *Book) AssetIDs() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.assetIDs()
+}
+
+// aggregatePriceLevels sums the displayed shares of orders sorted in
+// priority order, price by price.
+func aggregatePriceLevels(orders []*Order) []PriceLevel {
+	levels := []PriceLevel{}
+	for _, order := range orders {
+		shares := order.displayedShares()
+		if shares == 0 {
+			continue
+		}
+
+		if len(levels) == 0 || levels[len(levels)-1].Price != order.Price {
+			levels = append(levels, PriceLevel{Price: order.Price})
+		}
+		levels[len(levels)-1].Shares += shares
+		levels[len(levels)-1].Orders++
+	}
+	return levels
+}
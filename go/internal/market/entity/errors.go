@@ -0,0 +1,30 @@
+package entity
+
+import "errors"
+
+// Reasons the book gives when rejecting an order.
+var (
+	ErrPostOnlyWouldCross  = errors.New("post-only order would take liquidity")
+	ErrMarketClosed        = errors.New("market is closed")
+	ErrOutsideTradingHours = errors.New("order received outside trading hours")
+	ErrPriceOutsideBand    = errors.New("order price outside the price band")
+	ErrAssetHalted         = errors.New("trading is halted for the asset")
+	ErrMaxOrderNotional    = errors.New("order notional above the investor limit")
+	ErrMaxOrderShares      = errors.New("order shares above the investor limit")
+	ErrMaxOpenOrders       = errors.New("too many open orders for the investor")
+	ErrMaxPosition         = errors.New("order would take the position above the investor limit")
+	ErrMaxDailyTradedValue = errors.New("order would take the daily traded value above the investor limit")
+	ErrOrderRateExceeded   = errors.New("order rate above the investor limit")
+	ErrDuplicateOrderID    = errors.New("order ID already open for the investor")
+	ErrInvestorBlocked     = errors.New("investor blocked by the kill switch")
+)
+
+// Reasons the clearing house gives when a settlement fails.
+var (
+	ErrInsufficientHoldings = errors.New("insufficient settled holdings to deliver")
+)
+
+// Reasons the ledger gives when refusing a journal.
+var (
+	ErrUnbalancedJournal = errors.New("journal entries do not balance")
+)
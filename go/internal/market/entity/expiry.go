@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// expiryQueue is a heap of the open good-till-date orders, the one expiring
+// first on top. Orders leave it as soon as the book publishes them as no
+// longer open, so none of them can be recycled while still in it.
+type expiryQueue []*Order
+
+func (q expiryQueue) Len() int {
+	return len(q)
+}
+
+// expiresFirst reports whether order a expires before order b.
+func expiresFirst(a, b *Order) bool {
+	if !a.ExpiresAt.Equal(b.ExpiresAt) {
+		return a.ExpiresAt.Before(b.ExpiresAt)
+	}
+	return a.sequence < b.sequence
+}
+
+func (q *expiryQueue) push(order *Order) {
+	heapPush(q, order, expiresFirst)
+}
+
+func (q *expiryQueue) pop() *Order {
+	return heapPop(q, expiresFirst)
+}
+
+// remove takes an order out of the queue, if it is there.
+func (q *expiryQueue) remove(order *Order) {
+	for i, queued := range *q {
+		if queued == order {
+			heapRemove(q, i, expiresFirst)
+			return
+		}
+	}
+}
+
+// NextExpiry returns when the next good-till-date order expires, so a timer
+// can call Tick right on time. It reports false when no order is waiting to
+// expire.
+func (b *Book) NextExpiry() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.expiries.Len() == 0 {
+		return time.Time{}, false
+	}
+	return b.expiries[0].ExpiresAt, true
+}
+
+// trackExpiry registers a good-till-date order to be expired by Tick.
+func (b *Book) trackExpiry(order *Order) {
+	if order.TimeInForce == enums.GoodTillDate {
+		b.expiries.push(order)
+	}
+}
+
+// untrackExpiry stops tracking a good-till-date order that is no longer
+// open.
+func (b *Book) untrackExpiry(order *Order) {
+	if order.TimeInForce == enums.GoodTillDate {
+		b.expiries.remove(order)
+	}
+}
+
+// expireGoodTillDateOrders removes from the book, and from the orders queued
+// for the next session, every good-till-date order that has expired at now,
+// publishing each of them as expired.
+func (b *Book) expireGoodTillDateOrders(now time.Time) {
+	for b.expiries.Len() > 0 && b.expiries[0].IsExpired(now) {
+		order := b.expiries.pop()
+		assetID := order.Asset.ID
+		if !b.orderQueue(assetID, order.OrderType).Remove(order) {
+			b.unqueue(order)
+		}
+
+		order.Status = enums.Expired
+		b.publish(order)
+	}
+}
+
+// unqueue takes an order out of the orders queued for the next session of
+// its asset. It reports whether the order was queued.
+func (b *Book) unqueue(order *Order) bool {
+	queued := b.queued[order.Asset.ID]
+	for i, queuedOrder := range queued {
+		if queuedOrder == order {
+			b.queued[order.Asset.ID] = append(queued[:i], queued[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package entity
+
+// FeeRate holds the fees charged on a trade, as a fraction of its total, to
+// the order adding liquidity (maker) and to the order taking it (taker).
+type FeeRate struct {
+	Maker float64
+	Taker float64
+}
+
+// FeeSchedule holds the fee rates charged by the book.
+//
+// Rates can be set per asset, per investor tier, or per asset and investor
+// tier. When looking a rate up the most specific one wins: asset and tier
+// first, then tier, then asset, falling back to the default rate.
+type FeeSchedule struct {
+	Default    FeeRate
+	Assets     map[string]FeeRate
+	Tiers      map[string]FeeRate
+	AssetTiers map[string]map[string]FeeRate
+}
+
+func NewFeeSchedule(defaultRate FeeRate) *FeeSchedule {
+	return &FeeSchedule{
+		Default:    defaultRate,
+		Assets:     map[string]FeeRate{},
+		Tiers:      map[string]FeeRate{},
+		AssetTiers: map[string]map[string]FeeRate{},
+	}
+}
+
+// SetAssetTierRate sets the rate charged to investors of a tier trading an
+// asset.
+func (f *FeeSchedule) SetAssetTierRate(assetID string, tier string, rate FeeRate) {
+	if f.AssetTiers[assetID] == nil {
+		f.AssetTiers[assetID] = map[string]FeeRate{}
+	}
+	f.AssetTiers[assetID][tier] = rate
+}
+
+// Rate returns the rate charged to investors of a tier trading an asset. A
+// nil schedule charges no fees.
+func (f *FeeSchedule) Rate(assetID string, tier string) FeeRate {
+	if f == nil {
+		return FeeRate{}
+	}
+	if rate, ok := f.AssetTiers[assetID][tier]; ok {
+		return rate
+	}
+	if rate, ok := f.Tiers[tier]; ok {
+		return rate
+	}
+	if rate, ok := f.Assets[assetID]; ok {
+		return rate
+	}
+	return f.Default
+}
+
+// Fee returns the fee charged to an order on a trade of the given total,
+// at the taker rate when the order took liquidity and at the maker rate
+// otherwise.
+func (f *FeeSchedule) Fee(order *Order, total float64, taker bool) float64 {
+	rate := f.Rate(order.Asset.ID, order.Investor.Tier)
+	if taker {
+		return total * rate.Taker
+	}
+	return total * rate.Maker
+}
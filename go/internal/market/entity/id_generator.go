@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator hands out the IDs of the transactions created by the book.
+type IDGenerator interface {
+	NewID() string
+}
+
+// UUIDGenerator is an IDGenerator of random UUIDs. Their randomness is read
+// from Rand when set, so a seeded source generates the same IDs on every
+// run, and from crypto/rand otherwise.
+type UUIDGenerator struct {
+	Rand io.Reader
+}
+
+func (g UUIDGenerator) NewID() string {
+	if g.Rand == nil {
+		return uuid.NewString()
+	}
+	return uuid.Must(uuid.NewRandomFromReader(g.Rand)).String()
+}
+
+// SequenceIDGenerator is an IDGenerator of increasing numbers following
+// Prefix. It is cheaper than generating UUIDs and, starting from the same
+// number, hands out the same IDs on every run. A book using it only keeps the
+// number on its transactions, see Transaction.ID.
+type SequenceIDGenerator struct {
+	Prefix string
+	last   atomic.Uint64
+}
+
+// NewSequenceIDGenerator creates a generator whose first ID is prefix
+// followed by 1.
+func NewSequenceIDGenerator(prefix string) *SequenceIDGenerator {
+	return &SequenceIDGenerator{Prefix: prefix}
+}
+
+func (g *SequenceIDGenerator) NewID() string {
+	return g.Prefix + strconv.FormatUint(g.next(), 10)
+}
+
+// next hands out the number of the next ID.
+func (g *SequenceIDGenerator) next() uint64 {
+	return g.last.Add(1)
+}
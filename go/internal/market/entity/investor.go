@@ -5,6 +5,8 @@ type Investor struct {
 	ID            string
 	Name          string
 	AssetPosition []*InvestorAssetPosition
+	// Tier is the fee tier of the investor.
+	Tier string
 }
 
 // NewInvestor creates a new Investor instance with the specified ID.
@@ -60,6 +62,69 @@ func (i *Investor) UpdateAssetPosition(assetID string, sharesCount int) {
 	assetPosition.Shares += sharesCount
 }
 
+// ApplyFill updates an investor's position in a specific asset after a fill.
+//
+// Fills increasing the position move its average cost towards the fill
+// price. Fills reducing the position realize the profit or loss of the
+// closed shares against the average cost; if the position flips from long to
+// short (or the other way around), the remaining shares are held at the fill
+// price. The filled shares are settled right away.
+//
+// Parameters:
+//   - assetID: The unique identifier of the filled asset.
+//   - sharesCount: The number of shares filled, positive when buying and
+//     negative when selling.
+//   - price: The price of the fill.
+func (i *Investor) ApplyFill(assetID string, sharesCount int, price float64) {
+	assetPosition := i.GetAssetPosition(assetID)
+
+	if assetPosition == nil {
+		assetPosition = NewInvestorAssetPosition(assetID, 0)
+		i.AssetPosition = append(i.AssetPosition, assetPosition)
+	}
+
+	assetPosition.applyFill(sharesCount, price, true)
+}
+
+// ApplyUnsettledFill updates an investor's position in a specific asset
+// after a fill, like ApplyFill, but holds the filled shares as unsettled
+// until SettleAssetPosition is called for them.
+//
+// Parameters:
+//   - assetID: The unique identifier of the filled asset.
+//   - sharesCount: The number of shares filled, positive when buying and
+//     negative when selling.
+//   - price: The price of the fill.
+func (i *Investor) ApplyUnsettledFill(assetID string, sharesCount int, price float64) {
+	assetPosition := i.GetAssetPosition(assetID)
+
+	if assetPosition == nil {
+		assetPosition = NewInvestorAssetPosition(assetID, 0)
+		i.AssetPosition = append(i.AssetPosition, assetPosition)
+	}
+
+	assetPosition.applyFill(sharesCount, price, false)
+}
+
+// SettleAssetPosition moves unsettled shares of an asset into the settled
+// position of the investor.
+//
+// Parameters:
+//   - assetID: The unique identifier of the settled asset.
+//   - sharesCount: The number of shares settled, positive when receiving and
+//     negative when delivering.
+func (i *Investor) SettleAssetPosition(assetID string, sharesCount int) {
+	assetPosition := i.GetAssetPosition(assetID)
+
+	if assetPosition == nil {
+		assetPosition = NewInvestorAssetPosition(assetID, 0)
+		i.AssetPosition = append(i.AssetPosition, assetPosition)
+	}
+
+	assetPosition.Shares += sharesCount
+	assetPosition.UnsettledShares -= sharesCount
+}
+
 // GetAssetPosition retrieves the asset position for a specific asset by its ID.
 //
 // It searches through the investor's list of asset positions and returns the
@@ -83,11 +148,16 @@ func (i *Investor) GetAssetPosition(assetID string) *InvestorAssetPosition {
 
 // InvestorAssetPosition represents an investor's position in a specific asset.
 //
-// It includes information about the asset's unique identifier (AssetID) and the
-// number of shares (or "cotas") the investor holds in that asset.
+// It includes information about the asset's unique identifier (AssetID), the
+// number of settled shares (or "cotas") the investor holds in that asset, the
+// number of traded shares still waiting for settlement, the average cost paid
+// for them and the profit or loss realized by selling them.
 type InvestorAssetPosition struct {
-	AssetID string
-	Shares  int
+	AssetID         string
+	Shares          int
+	UnsettledShares int
+	AverageCost     float64
+	RealizedPnL     float64
 }
 
 func NewInvestorAssetPosition(assetID string, shares int) *InvestorAssetPosition {
@@ -96,3 +166,41 @@ func NewInvestorAssetPosition(assetID string, shares int) *InvestorAssetPosition
 		Shares:  shares,
 	}
 }
+
+// TotalShares returns the settled and unsettled shares of the position.
+func (p *InvestorAssetPosition) TotalShares() int {
+	return p.Shares + p.UnsettledShares
+}
+
+func (p *InvestorAssetPosition) applyFill(sharesCount int, price float64, settled bool) {
+	if sharesCount == 0 {
+		return
+	}
+
+	position := p.TotalShares()
+	if settled {
+		p.Shares += sharesCount
+	} else {
+		p.UnsettledShares += sharesCount
+	}
+	newPosition := p.TotalShares()
+
+	if position == 0 || (position > 0) == (sharesCount > 0) {
+		p.AverageCost = (p.AverageCost*float64(absInt(position)) + price*float64(absInt(sharesCount))) / float64(absInt(newPosition))
+		return
+	}
+
+	closedShares := minInt(absInt(sharesCount), absInt(position))
+	if position > 0 {
+		p.RealizedPnL += float64(closedShares) * (price - p.AverageCost)
+	} else {
+		p.RealizedPnL += float64(closedShares) * (p.AverageCost - price)
+	}
+
+	switch {
+	case newPosition == 0:
+		p.AverageCost = 0
+	case (newPosition > 0) != (position > 0):
+		p.AverageCost = price
+	}
+}
@@ -0,0 +1,45 @@
+package entity
+
+// KillSwitch pulls every order of an investor out of the book and blocks the
+// investor from sending new ones until ReleaseKillSwitch is called. When
+// assetID is empty every asset is affected, otherwise only the given one.
+//
+// Each cancellation is published, which also releases the order from the
+// investor's risk limits. The cancelled orders are returned.
+func (b *Book) KillSwitch(investorID string, assetID string) []*Order {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if b.blocked[investorID] == nil {
+		b.blocked[investorID] = make(map[string]bool)
+	}
+	b.blocked[investorID][assetID] = true
+
+	assetIDs := []string{assetID}
+	if assetID == "" {
+		assetIDs = b.assetIDs()
+	}
+
+	cancelled := []*Order{}
+	for _, assetID := range assetIDs {
+		cancelled = append(cancelled, b.cancelOrders(assetID, func(order *Order) bool {
+			return order.Investor.ID == investorID
+		})...)
+	}
+	return cancelled
+}
+
+// ReleaseKillSwitch lets an investor send orders again.
+func (b *Book) ReleaseKillSwitch(investorID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.blocked, investorID)
+}
+
+// isBlocked reports whether the kill switch of the order's investor covers
+// the order's asset.
+func (b *Book) isBlocked(order *Order) bool {
+	blockedAssets := b.blocked[order.Investor.ID]
+	return blockedAssets[""] || blockedAssets[order.Asset.ID]
+}
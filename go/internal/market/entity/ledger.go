@@ -0,0 +1,194 @@
+package entity
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// CashInstrument is the instrument cash balances are held in.
+	CashInstrument = "CASH"
+	// HouseAccountID is the account collecting the fees charged by the book.
+	HouseAccountID = "HOUSE"
+	// ExternalAccountID is the counterpart of everything entering or leaving
+	// the market, such as deposits and opening balances.
+	ExternalAccountID = "EXTERNAL"
+)
+
+// ledgerTolerance absorbs floating point noise when checking balances,
+// relative to the amounts involved.
+const ledgerTolerance = 1e-9
+
+// LedgerEntry moves an amount of an instrument (cash or an asset ID) in or
+// out of an account: positive amounts increase the account's balance and
+// negative amounts decrease it.
+type LedgerEntry struct {
+	Sequence   uint64
+	JournalID  string
+	AccountID  string
+	Instrument string
+	Amount     float64
+	DateTime   time.Time
+	Memo       string
+}
+
+// ReconciliationBreak is a difference between the shares an investor holds
+// in an asset and the balance the ledger has for them.
+type ReconciliationBreak struct {
+	InvestorID     string
+	AssetID        string
+	PositionShares int
+	LedgerBalance  float64
+}
+
+// Ledger is an append-only double-entry record of every cash and securities
+// movement. Entries are grouped in journals which must balance, per
+// instrument, to zero; balances are the sum of the entries of an account.
+type Ledger struct {
+	mu       sync.Mutex
+	entries  []LedgerEntry
+	balances map[string]map[string]float64
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{
+		entries:  []LedgerEntry{},
+		balances: map[string]map[string]float64{},
+	}
+}
+
+// Post appends a journal of entries to the ledger, stamping each of them
+// with the journal ID, date time and memo given. Journals that do not
+// balance per instrument are refused with ErrUnbalancedJournal.
+func (l *Ledger) Post(journalID string, dateTime time.Time, memo string, entries ...LedgerEntry) error {
+	totals := map[string]float64{}
+	gross := map[string]float64{}
+	for _, entry := range entries {
+		totals[entry.Instrument] += entry.Amount
+		gross[entry.Instrument] += math.Abs(entry.Amount)
+	}
+	for instrument, total := range totals {
+		if math.Abs(total) > ledgerTolerance*math.Max(1, gross[instrument]) {
+			return ErrUnbalancedJournal
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.Sequence = uint64(len(l.entries)) + 1
+		entry.JournalID = journalID
+		entry.DateTime = dateTime
+		entry.Memo = memo
+		l.entries = append(l.entries, entry)
+
+		if l.balances[entry.AccountID] == nil {
+			l.balances[entry.AccountID] = map[string]float64{}
+		}
+		l.balances[entry.AccountID][entry.Instrument] += entry.Amount
+	}
+	return nil
+}
+
+// PostTransaction posts the movements of a transaction: the shares go from
+// the seller to the buyer, the total from the buyer to the seller, and the
+// fee of each side to the house. Every movement is posted as a pair of
+// opposite entries of the same amount, so the journal balances exactly
+// whatever the size of the amounts.
+func (l *Ledger) PostTransaction(t *Transaction) error {
+	buyerID := t.BuyingOrder.Investor.ID
+	sellerID := t.SellingOrder.Investor.ID
+	assetID := t.SellingOrder.Asset.ID
+	shares := float64(t.Shares)
+
+	return l.Post(t.ID(), t.DateTime, "transaction",
+		LedgerEntry{AccountID: sellerID, Instrument: assetID, Amount: -shares},
+		LedgerEntry{AccountID: buyerID, Instrument: assetID, Amount: shares},
+		LedgerEntry{AccountID: buyerID, Instrument: CashInstrument, Amount: -t.Total},
+		LedgerEntry{AccountID: sellerID, Instrument: CashInstrument, Amount: t.Total},
+		LedgerEntry{AccountID: buyerID, Instrument: CashInstrument, Amount: -t.BuyerFee},
+		LedgerEntry{AccountID: HouseAccountID, Instrument: CashInstrument, Amount: t.BuyerFee},
+		LedgerEntry{AccountID: sellerID, Instrument: CashInstrument, Amount: -t.SellerFee},
+		LedgerEntry{AccountID: HouseAccountID, Instrument: CashInstrument, Amount: t.SellerFee},
+	)
+}
+
+// Deposit posts an amount of an instrument entering an account from outside
+// the market.
+func (l *Ledger) Deposit(journalID string, dateTime time.Time, accountID string, instrument string, amount float64) error {
+	return l.Post(journalID, dateTime, "deposit",
+		LedgerEntry{AccountID: ExternalAccountID, Instrument: instrument, Amount: -amount},
+		LedgerEntry{AccountID: accountID, Instrument: instrument, Amount: amount},
+	)
+}
+
+// OpenPositions posts the positions an investor already holds as opening
+// balances, so the ledger can be reconciled with them from then on.
+func (l *Ledger) OpenPositions(investor *Investor, dateTime time.Time) error {
+	for _, assetPosition := range investor.AssetPosition {
+		err := l.Deposit("opening:"+investor.ID+":"+assetPosition.AssetID, dateTime, investor.ID, assetPosition.AssetID, float64(assetPosition.TotalShares()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Entries returns a copy of every entry of the ledger, in posting order.
+func (l *Ledger) Entries() []LedgerEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]LedgerEntry{}, l.entries...)
+}
+
+// Balance returns the balance an account holds of an instrument.
+func (l *Ledger) Balance(accountID string, instrument string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.balances[accountID][instrument]
+}
+
+// Reconcile checks that the positions of the investors, settled and
+// unsettled, equal their balances in the ledger. It returns every difference
+// found, sorted by investor and asset; none means the books reconcile.
+func (l *Ledger) Reconcile(investors ...*Investor) []ReconciliationBreak {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	breaks := []ReconciliationBreak{}
+	for _, investor := range investors {
+		positions := map[string]int{}
+		for _, assetPosition := range investor.AssetPosition {
+			positions[assetPosition.AssetID] += assetPosition.TotalShares()
+		}
+
+		assetIDs := []string{}
+		for assetID := range positions {
+			assetIDs = append(assetIDs, assetID)
+		}
+		for instrument := range l.balances[investor.ID] {
+			if _, ok := positions[instrument]; !ok && instrument != CashInstrument {
+				assetIDs = append(assetIDs, instrument)
+			}
+		}
+		sort.Strings(assetIDs)
+
+		for _, assetID := range assetIDs {
+			balance := l.balances[investor.ID][assetID]
+			if math.Abs(balance-float64(positions[assetID])) > ledgerTolerance {
+				breaks = append(breaks, ReconciliationBreak{
+					InvestorID:     investor.ID,
+					AssetID:        assetID,
+					PositionShares: positions[assetID],
+					LedgerBalance:  balance,
+				})
+			}
+		}
+	}
+	return breaks
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logOrder logs a stage of an order going through the book, identified by
+// its ID, investor, asset, trace ID and, once the book has given it one, its
+// arrival number.
+func (b *Book) logOrder(msg string, order *Order, attrs ...slog.Attr) {
+	ctx := context.Background()
+	if !b.Logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs = append(attrs,
+		slog.String("order_id", order.ID),
+		slog.String("investor_id", order.Investor.ID),
+		slog.String("asset_id", order.Asset.ID),
+		slog.String("trace_id", order.TraceID),
+		slog.String("side", order.OrderType.String()),
+		slog.Float64("price", order.Price),
+		slog.Int("pending_shares", order.PendingShares),
+		slog.String("status", order.Status.String()),
+	)
+	if order.sequence != 0 {
+		attrs = append(attrs, slog.Uint64("sequence", order.sequence))
+	}
+	if order.RejectReason != nil {
+		attrs = append(attrs, slog.String("reason", order.RejectReason.Error()))
+	}
+	b.Logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+}
+
+// logTransaction logs a transaction executed by the book.
+func (b *Book) logTransaction(t *Transaction) {
+	ctx := context.Background()
+	if !b.Logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	b.Logger.LogAttrs(ctx, slog.LevelDebug, "transaction executed",
+		slog.String("transaction_id", t.ID()),
+		slog.String("asset_id", t.SellingOrder.Asset.ID),
+		slog.String("trace_id", t.TraceID),
+		slog.Float64("price", t.Price),
+		slog.Int("shares", t.Shares),
+		slog.String("aggressor", t.Aggressor.String()),
+		slog.String("buy_order_id", t.BuyingOrder.ID),
+		slog.Uint64("buy_sequence", t.BuyingOrder.sequence),
+		slog.String("buyer_id", t.BuyingOrder.Investor.ID),
+		slog.String("sell_order_id", t.SellingOrder.ID),
+		slog.Uint64("sell_sequence", t.SellingOrder.sequence),
+		slog.String("seller_id", t.SellingOrder.Investor.ID),
+	)
+}
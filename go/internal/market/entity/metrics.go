@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Metrics receives the measurements of the book as orders go through it.
+// Implementations must be safe for concurrent use and cheap to call, as
+// they are called while the book is locked.
+type Metrics interface {
+	// OrderReceived is called for every order handed to the book.
+	OrderReceived(order *Order)
+	// OrderProcessed is called once the book is done with an incoming order,
+	// with how long it took to process it.
+	OrderProcessed(order *Order, latency time.Duration)
+	// OrderRejected is called for every rejected order.
+	OrderRejected(order *Order)
+	// TransactionExecuted is called for every executed transaction.
+	TransactionExecuted(t *Transaction)
+}
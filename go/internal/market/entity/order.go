@@ -1,6 +1,10 @@
 package entity
 
-import "github.com/medina325/stock_market/go/internal/market/enums"
+import (
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
 
 type Order struct {
 	ID            string
@@ -9,12 +13,47 @@ type Order struct {
 	Shares        int
 	PendingShares int
 	Price         float64
-	OrderType     int
-	Status        int
+	OrderType     enums.Side
+	Status        enums.OrderStatus
 	Transactions  []*Transaction
+
+	// DisplayShares is the size of each slice an iceberg order shows in the
+	// book; zero means the whole order is displayed.
+	DisplayShares int
+	// VisibleShares is what is left of the slice currently displayed by an
+	// iceberg order.
+	VisibleShares int
+
+	// PostOnly orders only ever add liquidity: if they would cross the book on
+	// arrival they are rejected, or re-priced when the book is configured to.
+	PostOnly bool
+	// Hidden orders rest in the book and can be matched, but never show in
+	// the book depth.
+	Hidden bool
+
+	// TimeInForce tells how long the order stays in the book.
+	TimeInForce enums.TimeInForce
+	// ExpiresAt is when a good-till-date order expires.
+	ExpiresAt time.Time
+
+	// RejectReason tells why the book rejected the order.
+	RejectReason error
+	// TraceID correlates the order, the updates published for it and the
+	// transactions it took part in as the aggressor with the request that
+	// brought it in.
+	TraceID string
+
+	// sequence is the arrival number assigned by the book, used to keep
+	// time priority between orders resting at the same price.
+	sequence uint64
+
+	// level, prev and next link the order in the time queue of its price
+	// level while it rests on a PriceLevelSide.
+	level      *priceLevel
+	prev, next *Order
 }
 
-func NewOrder(orderID string, investor *Investor, asset *Asset, shares int, price float64, orderType int) *Order {
+func NewOrder(orderID string, investor *Investor, asset *Asset, shares int, price float64, orderType enums.Side) *Order {
 	return &Order{
 		ID:            orderID,
 		Investor:      investor,
@@ -28,6 +67,61 @@ func NewOrder(orderID string, investor *Investor, asset *Asset, shares int, pric
 	}
 }
 
+// NewIcebergOrder creates an order for shares in total that only shows
+// displayShares of them in the book at a time.
+func NewIcebergOrder(orderID string, investor *Investor, asset *Asset, shares int, displayShares int, price float64, orderType enums.Side) *Order {
+	order := NewOrder(orderID, investor, asset, shares, price, orderType)
+	order.DisplayShares = displayShares
+	order.replenish()
+	return order
+}
+
+// NewGoodTillDateOrder creates an order that expires at expiresAt.
+func NewGoodTillDateOrder(orderID string, investor *Investor, asset *Asset, shares int, price float64, orderType enums.Side, expiresAt time.Time) *Order {
+	order := NewOrder(orderID, investor, asset, shares, price, orderType)
+	order.TimeInForce = enums.GoodTillDate
+	order.ExpiresAt = expiresAt
+	return order
+}
+
+// IsExpired reports whether a good-till-date order has expired at now.
+func (o *Order) IsExpired(now time.Time) bool {
+	return o.TimeInForce == enums.GoodTillDate && !now.Before(o.ExpiresAt)
+}
+
+// IsIceberg reports whether the order only displays a slice of its size.
+func (o *Order) IsIceberg() bool {
+	return o.DisplayShares > 0 && o.DisplayShares < o.Shares
+}
+
+// matchableShares returns how many shares a resting order can trade before it
+// needs to be put back in the book.
+func (o *Order) matchableShares() int {
+	if o.IsIceberg() {
+		return minInt(o.VisibleShares, o.PendingShares)
+	}
+	return o.PendingShares
+}
+
+// displayedShares returns how many shares of a resting order are shown in the
+// book depth.
+func (o *Order) displayedShares() int {
+	if o.Hidden {
+		return 0
+	}
+	if o.IsIceberg() {
+		return minInt(o.VisibleShares, o.PendingShares)
+	}
+	return o.PendingShares
+}
+
+// replenish shows a new slice of an iceberg order.
+func (o *Order) replenish() {
+	if o.IsIceberg() {
+		o.VisibleShares = minInt(o.DisplayShares, o.PendingShares)
+	}
+}
+
 func (o *Order) AddTransaction(t *Transaction) {
 	o.Transactions = append(o.Transactions, t)
 }
@@ -35,3 +129,10 @@ func (o *Order) AddTransaction(t *Transaction) {
 func (o *Order) TransactionsCount() int {
 	return len(o.Transactions)
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
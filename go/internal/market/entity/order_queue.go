@@ -1,13 +1,38 @@
 package entity
 
+import (
+	"sort"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// OrderQueue is a heap of the orders resting on one side of an asset's book.
+// The best priced order sits on top (highest price for buy orders, lowest
+// price for sell orders) and orders at the same price are kept in arrival
+// order.
+//
+// OrderQueue implements heap.Interface, but as a BookSide it goes through
+// typed heap functions, which don't box the orders.
 type OrderQueue []*Order
 
+// hasPriority reports whether order a is matched before order b, both being
+// on the same side of the same book.
+func hasPriority(a, b *Order) bool {
+	if a.Price != b.Price {
+		if a.OrderType == enums.Buy {
+			return a.Price > b.Price
+		}
+		return a.Price < b.Price
+	}
+	return a.sequence < b.sequence
+}
+
 func (o OrderQueue) Len() int {
 	return len(o)
 }
 
 func (o OrderQueue) Less(i, j int) bool {
-	return o[i].Price < o[j].Price
+	return hasPriority(o[i], o[j])
 }
 
 func (o *OrderQueue) Swap(i, j int) {
@@ -26,14 +51,104 @@ func (o *OrderQueue) Pop() interface{} {
 	old := *o
 	length := len(old)
 
-	// last := old[length - 1]
-	// Removing last element
+	last := old[length-1]
+	// Clearing the reference so the popped order can be garbage collected
+	old[length-1] = nil
 	*o = old[0 : length-1]
 
-	return old[length-1]
+	return last
+}
+
+// Remove takes an order out of the queue, keeping the heap ordering. It
+// reports whether the order was in the queue.
+func (o *OrderQueue) Remove(order *Order) bool {
+	for i, queued := range *o {
+		if queued == order {
+			heapRemove(o, i, hasPriority)
+			return true
+		}
+	}
+	return false
+}
+
+// Best returns the order on top of the queue, or nil when it is empty.
+func (o OrderQueue) Best() *Order {
+	if len(o) == 0 {
+		return nil
+	}
+	return o[0]
+}
+
+// Add pushes an order to the queue.
+func (o *OrderQueue) Add(order *Order) {
+	heapPush(o, order, hasPriority)
+}
+
+// PopBest removes and returns the order on top of the queue, or nil when it
+// is empty.
+func (o *OrderQueue) PopBest() *Order {
+	if len(*o) == 0 {
+		return nil
+	}
+	return heapPop(o, hasPriority)
 }
 
-// Praticar essas coisas de container/heap no meu repo de go
+// RemoveIf takes the orders matching remove out of the queue, returning them
+// in the priority order they had in the queue.
+func (o *OrderQueue) RemoveIf(remove func(order *Order) bool) []*Order {
+	kept := (*o)[:0]
+	removed := []*Order{}
+	for _, order := range *o {
+		if remove(order) {
+			removed = append(removed, order)
+		} else {
+			kept = append(kept, order)
+		}
+	}
+
+	if len(removed) == 0 {
+		return removed
+	}
+
+	for i := len(kept); i < len(*o); i++ {
+		(*o)[i] = nil
+	}
+	*o = kept
+	heapInit(*o, hasPriority)
+
+	removedQueue := OrderQueue(removed)
+	sort.Sort(&removedQueue)
+	return removed
+}
+
+// Find looks the queue over for the investor's order with the given ID.
+func (o OrderQueue) Find(investorID string, orderID string) *Order {
+	for _, order := range o {
+		if order.ID == orderID && order.Investor.ID == investorID {
+			return order
+		}
+	}
+	return nil
+}
+
+// Orders returns a copy of the queue sorted in priority order.
+func (o OrderQueue) Orders() []*Order {
+	orders := append(OrderQueue{}, o...)
+	sort.Sort(&orders)
+	return orders
+}
+
+// Depth sorts a copy of the queue to aggregate its displayed shares.
+func (o OrderQueue) Depth() []PriceLevel {
+	return aggregatePriceLevels(o.Orders())
+}
+
+// Each calls fn for every order of the queue, in heap order.
+func (o OrderQueue) Each(fn func(order *Order)) {
+	for _, order := range o {
+		fn(order)
+	}
+}
 
 func NewOrderQueue() *OrderQueue {
 	return &OrderQueue{}
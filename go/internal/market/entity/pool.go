@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"sync"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// TransactionPool recycles the transactions of a book, so matching does not
+// allocate them once the pool is warm. A book with a pool hands every transaction
+// back to it as soon as it is executed and its orders are published: the
+// transactions are then kept neither in Book.Transactions nor in the
+// Transactions of their orders, and whatever needs one past the book's hooks
+// must copy it.
+type TransactionPool struct {
+	mu   sync.Mutex
+	free []*Transaction
+}
+
+// NewTransactionPool creates a pool holding size transactions up front.
+func NewTransactionPool(size int) *TransactionPool {
+	p := &TransactionPool{free: make([]*Transaction, 0, size)}
+	for i := 0; i < size; i++ {
+		p.free = append(p.free, &Transaction{})
+	}
+	return p
+}
+
+func (p *TransactionPool) get() *Transaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.free) == 0 {
+		return &Transaction{}
+	}
+	t := p.free[len(p.free)-1]
+	p.free[len(p.free)-1] = nil
+	p.free = p.free[:len(p.free)-1]
+	return t
+}
+
+func (p *TransactionPool) put(t *Transaction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	*t = Transaction{}
+	p.free = append(p.free, t)
+}
+
+// OrderPool recycles orders. An order can be put back once the book is done
+// with it, that is once it has been published as no longer open: the book
+// then holds no reference to it, in its sides, its queued orders or the
+// good-till-date orders it tracks. A pooled order must not be used
+// afterwards.
+type OrderPool struct {
+	mu   sync.Mutex
+	free []*Order
+}
+
+// NewOrderPool creates a pool holding size orders up front.
+func NewOrderPool(size int) *OrderPool {
+	p := &OrderPool{free: make([]*Order, 0, size)}
+	for i := 0; i < size; i++ {
+		p.free = append(p.free, &Order{Transactions: []*Transaction{}})
+	}
+	return p
+}
+
+// Get returns an order set up as NewOrder would, reusing a pooled one when
+// available.
+func (p *OrderPool) Get(orderID string, investor *Investor, asset *Asset, shares int, price float64, orderType enums.Side) *Order {
+	p.mu.Lock()
+	var order *Order
+	if len(p.free) == 0 {
+		order = &Order{Transactions: []*Transaction{}}
+	} else {
+		order = p.free[len(p.free)-1]
+		p.free[len(p.free)-1] = nil
+		p.free = p.free[:len(p.free)-1]
+	}
+	p.mu.Unlock()
+
+	*order = Order{
+		ID:            orderID,
+		Investor:      investor,
+		Asset:         asset,
+		Shares:        shares,
+		PendingShares: shares,
+		Price:         price,
+		OrderType:     orderType,
+		Status:        enums.Open,
+		Transactions:  order.Transactions[:0],
+	}
+	return order
+}
+
+// Put gives an order back to the pool.
+func (p *OrderPool) Put(order *Order) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.free = append(p.free, order)
+}
@@ -0,0 +1,87 @@
+package entity
+
+// PositionValuation is the value of an investor's position in an asset,
+// marked to a price.
+//
+// Shares counts both settled and unsettled shares, the latter also being
+// reported on their own.
+type PositionValuation struct {
+	AssetID         string
+	Shares          int
+	UnsettledShares int
+	AverageCost     float64
+	MarkPrice       float64
+	MarketValue     float64
+	CostBasis       float64
+	UnrealizedPnL   float64
+	RealizedPnL     float64
+}
+
+// PortfolioSummary is the value of every position of an investor.
+type PortfolioSummary struct {
+	InvestorID    string
+	Positions     []PositionValuation
+	MarketValue   float64
+	CostBasis     float64
+	UnrealizedPnL float64
+	RealizedPnL   float64
+	TotalPnL      float64
+}
+
+// Portfolio values every position of the investor at the mark prices, keyed
+// by asset ID. Positions of assets without a mark price are valued at their
+// average cost.
+func (i *Investor) Portfolio(markPrices map[string]float64) *PortfolioSummary {
+	summary := &PortfolioSummary{
+		InvestorID: i.ID,
+		Positions:  []PositionValuation{},
+	}
+
+	for _, assetPosition := range i.AssetPosition {
+		markPrice, ok := markPrices[assetPosition.AssetID]
+		if !ok {
+			markPrice = assetPosition.AverageCost
+		}
+
+		shares := float64(assetPosition.TotalShares())
+		valuation := PositionValuation{
+			AssetID:         assetPosition.AssetID,
+			Shares:          assetPosition.TotalShares(),
+			UnsettledShares: assetPosition.UnsettledShares,
+			AverageCost:     assetPosition.AverageCost,
+			MarkPrice:       markPrice,
+			MarketValue:     shares * markPrice,
+			CostBasis:       shares * assetPosition.AverageCost,
+			UnrealizedPnL:   shares * (markPrice - assetPosition.AverageCost),
+			RealizedPnL:     assetPosition.RealizedPnL,
+		}
+
+		summary.Positions = append(summary.Positions, valuation)
+		summary.MarketValue += valuation.MarketValue
+		summary.CostBasis += valuation.CostBasis
+		summary.UnrealizedPnL += valuation.UnrealizedPnL
+		summary.RealizedPnL += valuation.RealizedPnL
+	}
+
+	summary.TotalPnL = summary.UnrealizedPnL + summary.RealizedPnL
+	return summary
+}
+
+// LastPrice returns the price an asset last traded at in the book. It
+// reports false when the asset has not traded yet.
+func (b *Book) LastPrice(assetID string) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	price, ok := b.lastPrices[assetID]
+	return price, ok
+}
+
+// Portfolio values every position of an investor marked to the last trade
+// price of each asset in the book.
+func (b *Book) Portfolio(investor *Investor) *PortfolioSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return investor.Portfolio(b.lastPrices)
+}
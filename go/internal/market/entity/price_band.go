@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+const volatilityHaltReason = "volatility halt"
+
+// PriceBand protects an asset from trading at prices far away from where it
+// has been trading. Deviations are fractions of the price they are measured
+// against (0.1 being 10%), and zero disables the respective check.
+type PriceBand struct {
+	// Static is the maximum deviation of an order price from the previous
+	// close of the asset.
+	Static float64
+	// Dynamic is the maximum deviation of an order price from the last trade
+	// price of the asset.
+	Dynamic float64
+	// HaltThreshold is the maximum move from the reference price a single
+	// transaction may cause before matching is halted for the asset.
+	HaltThreshold float64
+	// CoolingOff is how long a volatility halt lasts before trading resumes
+	// with an auction.
+	CoolingOff time.Duration
+}
+
+func deviates(price float64, reference float64, limit float64) bool {
+	return limit > 0 && reference > 0 && math.Abs(price-reference)/reference > limit
+}
+
+// referencePrice returns the price an asset is anchored to: its last trade
+// price or, when it has not traded yet, its previous close.
+func (b *Book) referencePrice(asset *Asset) float64 {
+	if price, ok := b.lastPrices[asset.ID]; ok {
+		return price
+	}
+	return asset.ReferencePrice
+}
+
+// checkPriceBand reports whether an order price is within the price band of
+// its asset.
+func (b *Book) checkPriceBand(order *Order) bool {
+	band := b.PriceBands[order.Asset.ID]
+	if band == nil {
+		return true
+	}
+
+	if deviates(order.Price, order.Asset.ReferencePrice, band.Static) {
+		return false
+	}
+
+	lastPrice, traded := b.lastPrices[order.Asset.ID]
+	return !traded || !deviates(order.Price, lastPrice, band.Dynamic)
+}
+
+// tripsCircuitBreaker reports whether trading an asset at price would move it
+// beyond the halt threshold of its price band, in which case the asset is
+// halted for the cooling-off period.
+func (b *Book) tripsCircuitBreaker(asset *Asset, price float64) bool {
+	band := b.PriceBands[asset.ID]
+	if band == nil || !deviates(price, b.referencePrice(asset), band.HaltThreshold) {
+		return false
+	}
+
+	b.haltedUntil[asset.ID] = b.Clock.Now().Add(band.CoolingOff)
+	b.setPhase(asset.ID, enums.Halted, volatilityHaltReason)
+	return true
+}
+
+// resumeHaltedAssets resumes trading, through an auction, on the assets
+// whose volatility halt is over.
+func (b *Book) resumeHaltedAssets(now time.Time) {
+	assetIDs := make([]string, 0, len(b.haltedUntil))
+	for assetID, until := range b.haltedUntil {
+		if !now.Before(until) {
+			assetIDs = append(assetIDs, assetID)
+		}
+	}
+	sort.Strings(assetIDs)
+
+	for _, assetID := range assetIDs {
+		if b.phase(assetID) != enums.Halted {
+			delete(b.haltedUntil, assetID)
+			continue
+		}
+		b.resume(assetID, "")
+	}
+}
@@ -0,0 +1,226 @@
+package entity
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// RiskLimits holds the pre-trade limits of an investor. A zero value
+// disables the respective limit.
+type RiskLimits struct {
+	// MaxOrderNotional is the maximum price * shares of a single order.
+	MaxOrderNotional float64 `json:"max_order_notional"`
+	// MaxOrderShares is the maximum number of shares of a single order.
+	MaxOrderShares int `json:"max_order_shares"`
+	// MaxOpenOrders is the maximum number of orders open at the same time.
+	MaxOpenOrders int `json:"max_open_orders"`
+	// MaxPosition is the maximum number of shares, long or short, the
+	// investor may hold of an asset if every open order were filled.
+	MaxPosition int `json:"max_position"`
+	// MaxDailyTradedValue is the maximum value the investor may trade in a
+	// day, counting the notional of the order being checked.
+	MaxDailyTradedValue float64 `json:"max_daily_traded_value"`
+	// MaxOrdersPerSecond is the maximum number of orders the investor may
+	// send within a second.
+	MaxOrdersPerSecond int `json:"max_orders_per_second"`
+}
+
+// RiskLimitsConfig is the document RiskGate.LoadLimits reads: default
+// limits, plus the limits of specific investors keyed by investor ID.
+type RiskLimitsConfig struct {
+	Default   RiskLimits            `json:"default"`
+	Investors map[string]RiskLimits `json:"investors"`
+}
+
+// RiskGate checks every order against the risk limits of its investor before
+// it reaches the book. Limits can be replaced at any time, even while the
+// book is trading.
+type RiskGate struct {
+	mu       sync.Mutex
+	clock    Clock
+	defaults RiskLimits
+	limits   map[string]RiskLimits
+
+	openOrders   map[string]map[string]*Order
+	tradingDay   string
+	dailyTraded  map[string]float64
+	recentOrders map[string][]time.Time
+}
+
+func NewRiskGate(clock Clock) *RiskGate {
+	return &RiskGate{
+		clock:        clock,
+		limits:       map[string]RiskLimits{},
+		openOrders:   map[string]map[string]*Order{},
+		dailyTraded:  map[string]float64{},
+		recentOrders: map[string][]time.Time{},
+	}
+}
+
+// SetDefaultLimits sets the limits of investors without limits of their own.
+func (g *RiskGate) SetDefaultLimits(limits RiskLimits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.defaults = limits
+}
+
+// SetLimits sets the limits of an investor.
+func (g *RiskGate) SetLimits(investorID string, limits RiskLimits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.limits[investorID] = limits
+}
+
+// LoadLimits replaces every limit of the gate with the ones of a JSON encoded
+// RiskLimitsConfig. The limits are left untouched when the document is
+// invalid.
+func (g *RiskGate) LoadLimits(r io.Reader) error {
+	config := RiskLimitsConfig{}
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return err
+	}
+
+	limits := make(map[string]RiskLimits, len(config.Investors))
+	for investorID, investorLimits := range config.Investors {
+		limits[investorID] = investorLimits
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.defaults = config.Default
+	g.limits = limits
+	return nil
+}
+
+// Limits returns the limits applied to an investor.
+func (g *RiskGate) Limits(investorID string) RiskLimits {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.limitsOf(investorID)
+}
+
+// Check returns why an order breaches the limits of its investor, or nil if
+// it doesn't, in which case the order is counted as open until released and
+// towards the order rate of the investor. Rejected orders count towards
+// neither, and an order reusing the ID of an open order of the investor is
+// rejected.
+func (g *RiskGate) Check(order *Order) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	investorID := order.Investor.ID
+	limits := g.limitsOf(investorID)
+	notional := order.Price * float64(order.Shares)
+
+	recent := g.recentOrders[investorID]
+	for len(recent) > 0 && now.Sub(recent[0]) >= time.Second {
+		recent = recent[1:]
+	}
+	g.recentOrders[investorID] = recent
+
+	if _, ok := g.openOrders[investorID][order.ID]; ok {
+		return ErrDuplicateOrderID
+	}
+	if limits.MaxOrdersPerSecond > 0 && len(recent) >= limits.MaxOrdersPerSecond {
+		return ErrOrderRateExceeded
+	}
+	if limits.MaxOrderShares > 0 && order.Shares > limits.MaxOrderShares {
+		return ErrMaxOrderShares
+	}
+	if limits.MaxOrderNotional > 0 && notional > limits.MaxOrderNotional {
+		return ErrMaxOrderNotional
+	}
+	if limits.MaxOpenOrders > 0 && len(g.openOrders[investorID]) >= limits.MaxOpenOrders {
+		return ErrMaxOpenOrders
+	}
+	if limits.MaxPosition > 0 && absInt(g.projectedPosition(order)) > limits.MaxPosition {
+		return ErrMaxPosition
+	}
+	if limits.MaxDailyTradedValue > 0 && g.tradedToday(investorID, now)+notional > limits.MaxDailyTradedValue {
+		return ErrMaxDailyTradedValue
+	}
+
+	if g.openOrders[investorID] == nil {
+		g.openOrders[investorID] = map[string]*Order{}
+	}
+	g.openOrders[investorID][order.ID] = order
+	g.recentOrders[investorID] = append(recent, now)
+	return nil
+}
+
+// AddTransaction accounts a transaction in the daily traded value of the
+// buyer and the seller.
+func (g *RiskGate) AddTransaction(t *Transaction) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.rollTradingDay(g.clock.Now())
+	g.dailyTraded[t.BuyingOrder.Investor.ID] += t.Total
+	g.dailyTraded[t.SellingOrder.Investor.ID] += t.Total
+}
+
+// Release stops counting an order as open. Releasing an order that was
+// never counted, such as one rejected for reusing an open order's ID, leaves
+// the open order counted.
+func (g *RiskGate) Release(order *Order) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.openOrders[order.Investor.ID][order.ID] == order {
+		delete(g.openOrders[order.Investor.ID], order.ID)
+	}
+}
+
+func (g *RiskGate) limitsOf(investorID string) RiskLimits {
+	if limits, ok := g.limits[investorID]; ok {
+		return limits
+	}
+	return g.defaults
+}
+
+// projectedPosition returns the position the investor would hold in the
+// order's asset if the order and every other open order of the investor in
+// that direction were filled.
+func (g *RiskGate) projectedPosition(order *Order) int {
+	position := 0
+	if assetPosition := order.Investor.GetAssetPosition(order.Asset.ID); assetPosition != nil {
+		position = assetPosition.TotalShares()
+	}
+
+	shares := order.Shares
+	for _, openOrder := range g.openOrders[order.Investor.ID] {
+		if openOrder.Asset.ID == order.Asset.ID && openOrder.OrderType == order.OrderType {
+			shares += openOrder.PendingShares
+		}
+	}
+
+	if order.OrderType == enums.Buy {
+		return position + shares
+	}
+	return position - shares
+}
+
+// tradedToday returns the value traded by an investor on the day of now.
+func (g *RiskGate) tradedToday(investorID string, now time.Time) float64 {
+	g.rollTradingDay(now)
+	return g.dailyTraded[investorID]
+}
+
+// rollTradingDay starts a new day of accounting when the day of now is not
+// the one being accounted.
+func (g *RiskGate) rollTradingDay(now time.Time) {
+	day := now.UTC().Format(dateLayout)
+	if day != g.tradingDay {
+		g.tradingDay = day
+		g.dailyTraded = map[string]float64{}
+	}
+}
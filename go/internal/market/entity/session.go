@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// Tick brings the book up to date with its clock: good-till-date orders
+// past their expiry are removed from the book, assets whose session has
+// opened since the last tick get their queued orders processed, assets whose
+// session has closed get their DAY orders expired, and assets whose
+// volatility halt is over resume trading.
+func (b *Book) Tick() {
+	b.mu.Lock()
+	defer b.unlock()
+
+	now := b.Clock.Now()
+
+	// Expiring first, so orders that expired while queued don't trade at the
+	// open.
+	b.expireGoodTillDateOrders(now)
+
+	assetIDs := make([]string, 0, len(b.Schedules))
+	for assetID := range b.Schedules {
+		assetIDs = append(assetIDs, assetID)
+	}
+	sort.Strings(assetIDs)
+
+	for _, assetID := range assetIDs {
+		b.syncSession(assetID, now)
+	}
+
+	b.resumeHaltedAssets(now)
+}
+
+// TickEvery calls Tick on the given interval of wall time until the returned
+// function is called.
+func (b *Book) TickEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				b.Tick()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// syncSession opens or closes the session of an asset according to its
+// trading schedule.
+func (b *Book) syncSession(assetID string, now time.Time) {
+	schedule := b.Schedules[assetID]
+	if schedule == nil {
+		return
+	}
+
+	open := schedule.IsOpen(now)
+	if open == b.sessionOpen[assetID] {
+		return
+	}
+	b.sessionOpen[assetID] = open
+
+	if open {
+		queued := b.queued[assetID]
+		delete(b.queued, assetID)
+		for _, order := range queued {
+			if order.IsExpired(now) {
+				order.Status = enums.Expired
+				b.publish(order)
+				continue
+			}
+			b.process(order)
+		}
+		return
+	}
+
+	b.expireOrders(assetID, func(order *Order) bool {
+		return order.TimeInForce == enums.Day
+	})
+}
+
+// expireOrders removes the resting orders of an asset matching expired from
+// the book, publishing each of them as expired.
+func (b *Book) expireOrders(assetID string, expired func(order *Order) bool) {
+	for _, side := range []enums.Side{enums.Buy, enums.Sell} {
+		for _, order := range b.orderQueue(assetID, side).RemoveIf(expired) {
+			order.Status = enums.Expired
+			b.publish(order)
+		}
+	}
+}
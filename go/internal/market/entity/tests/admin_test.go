@@ -0,0 +1,185 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaltAndResumeAsset(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	statusChanOut := make(chan *entity.AssetStatus, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.StatusChanOut = statusChanOut
+
+	book.Halt(a.ID, "news pending")
+
+	assert := assert.New(t)
+
+	status := <-statusChanOut
+	assert.Equal(a.ID, status.AssetID, "Status should be published for the halted asset")
+	assert.Equal(enums.Halted, status.Phase, "Asset should be halted")
+	assert.Equal("news pending", status.Reason, "Status should carry the halt reason")
+
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+	book.Process(sellOrder)
+	book.Process(buyOrder)
+
+	assert.Equal(0, buyOrder.TransactionsCount(), "Orders should not match while halted")
+
+	book.RejectWhileHalted = true
+	rejectedOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+	book.Process(rejectedOrder)
+
+	assert.Equal(rejectedOrder, <-chanOut, "Rejected order should be published")
+	assert.ErrorIs(rejectedOrder.RejectReason, entity.ErrAssetHalted)
+
+	wg.Add(1)
+	result := book.Resume(a.ID)
+	wg.Wait()
+
+	assert.Equal(10, result.Shares, "Resuming should uncross the book")
+	assert.Equal(enums.Closed, buyOrder.Status, "Buy order should be filled when resuming")
+	assert.Equal(enums.Continuous, (<-statusChanOut).Phase, "Resumed status should be published")
+}
+
+func TestHaltMarketAndCancelAll(t *testing.T) {
+	asset1 := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	asset2 := entity.NewAsset(uuid.NewString(), "Asset 2", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	statusChanOut := make(chan *entity.AssetStatus, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.StatusChanOut = statusChanOut
+
+	order1 := entity.NewOrder(uuid.NewString(), investor, asset1, 10, 5, enums.Buy)
+	order2 := entity.NewOrder(uuid.NewString(), investor, asset1, 10, 4, enums.Buy)
+	order3 := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	book.Process(order1)
+	book.Process(order2)
+	book.Process(order3)
+
+	book.HaltMarket("market wide halt")
+
+	assert := assert.New(t)
+
+	status := <-statusChanOut
+	assert.Equal("", status.AssetID, "Market wide status should have no asset")
+	assert.Equal(enums.Halted, book.Phase(asset1.ID), "Asset 1 should be halted")
+	assert.Equal(enums.Halted, book.Phase(asset2.ID), "Asset 2 should be halted")
+
+	cancelled := book.CancelAll(asset1.ID)
+
+	assert.Equal([]*entity.Order{order1, order2}, cancelled, "Every order of asset 1 should be cancelled in priority order")
+	assert.Equal(order1, <-chanOut, "Cancellation of order 1 should be published")
+	assert.Equal(order2, <-chanOut, "Cancellation of order 2 should be published")
+	assert.Equal(enums.Cancelled, order1.Status, "Order 1 should be cancelled")
+	assert.Equal(enums.Open, order3.Status, "Orders of other assets should not be cancelled")
+	assert.Empty(book.Depth(asset1.ID).Bids, "Asset 1 book should be empty")
+
+	book.ResumeMarket()
+
+	assert.Equal(enums.Continuous, book.Phase(asset2.ID), "Asset 2 should be trading again")
+}
+
+func TestResumeMarketRestoresAssetPhases(t *testing.T) {
+	halted := entity.NewAsset(uuid.NewString(), "Halted", 100)
+	preOpen := entity.NewAsset(uuid.NewString(), "Pre-open", 100)
+	trading := entity.NewAsset(uuid.NewString(), "Trading", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	for _, a := range []*entity.Asset{halted, preOpen, trading} {
+		sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	}
+
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+
+	book.Halt(halted.ID, "news pending")
+	book.SetPhase(preOpen.ID, enums.PreOpen)
+	book.HaltMarket("market wide halt")
+
+	// Crossing orders accumulate in every book during the halt.
+	orders := []*entity.Order{}
+	for _, a := range []*entity.Asset{halted, preOpen, trading} {
+		sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+		buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+		book.Process(sellOrder)
+		book.Process(buyOrder)
+		orders = append(orders, sellOrder, buyOrder)
+	}
+
+	results := book.ResumeMarket()
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Halted, book.Phase(halted.ID), "Asset halted on its own should stay halted")
+	assert.Equal(enums.PreOpen, book.Phase(preOpen.ID), "Asset in pre-open should stay in pre-open")
+	assert.Equal(enums.Continuous, book.Phase(trading.ID), "Trading asset should trade again")
+
+	assert.Len(results, 1, "Only the asset back in continuous trading should be uncrossed")
+	assert.Equal(trading.ID, results[0].AssetID)
+	assert.Equal(10, results[0].Shares)
+	for _, order := range orders[:4] {
+		assert.Equal(enums.Open, order.Status, "Orders of assets not trading should keep resting")
+		assert.Equal(0, order.TransactionsCount())
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+
+	order := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Buy)
+	other := entity.NewOrder(uuid.NewString(), investor, a, 10, 4, enums.Buy)
+	book.Process(order)
+	book.Process(other)
+
+	assert := assert.New(t)
+
+	assert.True(book.CancelOrder(order), "Resting order should be cancelled")
+	assert.Equal(order, <-chanOut, "Cancellation should be published")
+	assert.Equal(enums.Cancelled, order.Status)
+	assert.Equal([]*entity.Order{other}, book.RestingOrders(a.ID, enums.Buy), "Other orders should keep resting")
+
+	assert.False(book.CancelOrder(order), "Order should not be cancelled twice")
+	assert.Len(chanOut, 0)
+}
+
+func TestRestingTotals(t *testing.T) {
+	a := entity.NewAsset("ASSET1", "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 6, 10, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 4, 11, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 2, 10, enums.Buy))
+
+	assert := assert.New(t)
+	assert.Equal([]entity.SideTotals{
+		{AssetID: a.ID, Side: enums.Buy, Orders: 0, Shares: 0},
+		{AssetID: a.ID, Side: enums.Sell, Orders: 2, Shares: 8},
+	}, book.RestingTotals())
+}
@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpeningAuction(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	buyInvestor1 := entity.NewInvestor(uuid.NewString())
+	buyInvestor2 := entity.NewInvestor(uuid.NewString())
+	sellInvestor1 := entity.NewInvestor(uuid.NewString())
+	sellInvestor1.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 8))
+	sellInvestor2 := entity.NewInvestor(uuid.NewString())
+	sellInvestor2.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 6))
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.SetMarketPhase(enums.PreOpen)
+
+	buyOrder1 := entity.NewOrder(uuid.NewString(), buyInvestor1, a, 10, 10, enums.Buy)
+	buyOrder2 := entity.NewOrder(uuid.NewString(), buyInvestor2, a, 5, 9, enums.Buy)
+	sellOrder1 := entity.NewOrder(uuid.NewString(), sellInvestor1, a, 8, 8, enums.Sell)
+	sellOrder2 := entity.NewOrder(uuid.NewString(), sellInvestor2, a, 6, 9.5, enums.Sell)
+
+	for _, order := range []*entity.Order{buyOrder1, buyOrder2, sellOrder1, sellOrder2} {
+		book.Process(order)
+	}
+
+	assert := assert.New(t)
+
+	assert.Equal(0, buyOrder1.TransactionsCount(), "Orders should not match during pre-open")
+	assert.Len(book.Depth(a.ID).Bids, 2, "Buy orders should accumulate during pre-open")
+	assert.Len(book.Depth(a.ID).Asks, 2, "Sell orders should accumulate during pre-open")
+
+	indicative := book.IndicativeAuction(a.ID)
+	assert.Equal(9.5, indicative.Price, "Indicative price should maximize the executed volume")
+	assert.Equal(10, indicative.Shares, "Indicative volume should be of 10 shares")
+	assert.Equal(-4, indicative.Imbalance, "Indicative imbalance should be of 4 shares to sell")
+
+	wg.Add(2)
+	result := book.RunAuction(a.ID)
+	wg.Wait()
+
+	assert.Equal(9.5, result.Price, "Auction price should be the lowest price with the best volume under sell pressure")
+	assert.Equal(10, result.Shares, "Auction should execute 10 shares")
+	assert.Equal(enums.Continuous, book.Phase(a.ID), "Asset should trade continuously after the opening auction")
+
+	assert.Equal(enums.Closed, buyOrder1.Status, "Buy order 1 should be filled")
+	assert.Equal(2, buyOrder1.TransactionsCount(), "Buy order 1 should have 2 transactions")
+	assert.Equal(9.5, buyOrder1.Transactions[0].Price, "Every transaction should use the auction price")
+	assert.Equal(9.5, buyOrder1.Transactions[1].Price, "Every transaction should use the auction price")
+	assert.True(buyOrder1.Transactions[0].Auction, "Transaction should be flagged as an auction transaction")
+	assert.Equal(enums.Closed, sellOrder1.Status, "Sell order 1 should be filled")
+	assert.Equal(4, sellOrder2.PendingShares, "Sell order 2 should have 4 pending shares")
+	assert.Equal(5, buyOrder2.PendingShares, "Buy order 2 should not trade")
+
+	depth := book.Depth(a.ID)
+	assert.Equal([]entity.PriceLevel{{Price: 9, Shares: 5, Orders: 1}}, depth.Bids)
+	assert.Equal([]entity.PriceLevel{{Price: 9.5, Shares: 4, Orders: 1}}, depth.Asks)
+}
+
+func TestClosingAuction(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.SetPhase(a.ID, enums.PreClose)
+
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 11, enums.Buy)
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 9, enums.Sell)
+	book.Process(buyOrder)
+	book.Process(sellOrder)
+
+	wg.Add(1)
+	result := book.RunAuction(a.ID)
+	wg.Wait()
+
+	assert := assert.New(t)
+
+	assert.Equal(10, result.Shares, "Auction should execute 10 shares")
+	assert.Equal(9.0, result.Price, "Without a reference price the lowest of the prices closest to the middle should be picked")
+	assert.Equal(enums.MarketClosed, book.Phase(a.ID), "Asset should be closed after the closing auction")
+
+	<-chanOut
+	<-chanOut
+
+	lateOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 11, enums.Buy)
+	book.Process(lateOrder)
+
+	assert.Equal(lateOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, lateOrder.Status, "Orders should be rejected after the close")
+	assert.ErrorIs(lateOrder.RejectReason, entity.ErrMarketClosed)
+}
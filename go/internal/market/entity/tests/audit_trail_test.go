@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func eventTypes(events []entity.OrderEvent) []enums.OrderEventType {
+	types := []enums.OrderEventType{}
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestAuditTrailRecordsOrderEvents(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	clock := entity.NewFakeClock(time.Date(2023, 9, 8, 10, 0, 0, 0, time.UTC))
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+	book.AuditTrail = entity.NewAuditTrail()
+
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 10, enums.Buy)
+	postOnlyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 1, 10, enums.Buy)
+	postOnlyOrder.PostOnly = true
+
+	wg.Add(1)
+	book.Process(sellOrder)
+	clock.Advance(time.Second)
+	book.Process(buyOrder)
+	book.Process(postOnlyOrder)
+	wg.Wait()
+	book.CancelAll(a.ID)
+
+	assert := assert.New(t)
+
+	events := book.AuditTrail.Events()
+	assert.Len(events, 9)
+	for i, event := range events {
+		assert.Equal(uint64(i+1), event.Sequence, "Events should be numbered in processing order")
+	}
+
+	sellEvents := book.AuditTrail.OrderEvents(sellOrder.ID)
+	assert.Equal([]enums.OrderEventType{enums.OrderReceived, enums.OrderAccepted, enums.OrderFilled, enums.OrderCancelled}, eventTypes(sellEvents))
+	assert.Equal(sellInvestor.ID, sellEvents[0].InvestorID)
+	assert.Equal(a.ID, sellEvents[0].AssetID)
+	assert.Equal(clock.Now().Add(-time.Second), sellEvents[0].DateTime)
+	assert.Equal(4, sellEvents[2].Shares, "Fill should carry the transaction shares")
+	assert.Equal(6, sellEvents[2].PendingShares)
+	assert.Equal(book.Transactions[0].ID(), sellEvents[2].TransactionID)
+
+	buyEvents := book.AuditTrail.OrderEvents(buyOrder.ID)
+	assert.Equal([]enums.OrderEventType{enums.OrderReceived, enums.OrderAccepted, enums.OrderFilled}, eventTypes(buyEvents))
+	assert.Equal(enums.Closed, buyEvents[2].Status)
+
+	postOnlyEvents := book.AuditTrail.OrderEvents(postOnlyOrder.ID)
+	assert.Equal([]enums.OrderEventType{enums.OrderReceived, enums.OrderRejected}, eventTypes(postOnlyEvents))
+	assert.Equal(entity.ErrPostOnlyWouldCross.Error(), postOnlyEvents[1].Reason)
+}
+
+func TestAuditTrailExport(t *testing.T) {
+	trail := entity.NewAuditTrail()
+	friday := time.Date(2023, 9, 8, 23, 30, 0, 0, time.UTC)
+	trail.Record(entity.OrderEvent{DateTime: friday, Type: enums.OrderReceived, OrderID: "1", Side: enums.Buy, Price: 10.5, Shares: 5})
+	trail.Record(entity.OrderEvent{DateTime: friday.Add(time.Hour), Type: enums.OrderReceived, OrderID: "2", Side: enums.Sell, Price: 11, Shares: 3})
+
+	assert := assert.New(t)
+
+	var csvOutput bytes.Buffer
+	assert.NoError(trail.WriteCSV(&csvOutput, friday))
+	records, err := csv.NewReader(&csvOutput).ReadAll()
+	assert.NoError(err)
+	assert.Len(records, 2, "Export should hold the header and the events of the day")
+	assert.Equal("sequence", records[0][0])
+	assert.Equal([]string{"1", "2023-09-08T23:30:00Z", "RECEIVED", "1", "", "", "BUY", "10.5", "5", "0", "OPEN", "", ""}, records[1])
+
+	var jsonOutput bytes.Buffer
+	saturday := friday.Add(time.Hour)
+	assert.NoError(trail.WriteJSONLines(&jsonOutput, saturday))
+	lines := strings.Split(strings.TrimSpace(jsonOutput.String()), "\n")
+	assert.Len(lines, 1)
+
+	var event entity.OrderEvent
+	assert.NoError(json.Unmarshal([]byte(lines[0]), &event))
+	assert.Equal(uint64(2), event.Sequence)
+	assert.Equal(enums.Sell, event.Side)
+
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	assert.Len(trail.Day(friday.In(saoPaulo)), 2, "Trading days should be taken in the location of the day given")
+}
@@ -0,0 +1,196 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/medina325/stock_market/go/internal/market/loadgen"
+)
+
+// newBenchmarkBook creates a book whose output channel is drained for the
+// duration of the benchmark.
+func newBenchmarkBook(b *testing.B) *entity.Book {
+	chanOut := make(chan *entity.Order, 1024)
+	go func() {
+		for range chanOut {
+		}
+	}()
+	b.Cleanup(func() { close(chanOut) })
+
+	book := entity.NewBook(nil, chanOut, nil)
+	book.IDGenerator = entity.NewSequenceIDGenerator("")
+	return book
+}
+
+// newBenchmarkGenerator creates a generator of the flow described by config.
+func newBenchmarkGenerator(b *testing.B, config loadgen.Config) *loadgen.Generator {
+	generator, err := loadgen.NewGenerator(config)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return generator
+}
+
+// prefillBook rests depth price levels of non crossing orders on each side
+// of every asset of the generator.
+func prefillBook(book *entity.Book, generator *loadgen.Generator, depth int) {
+	investor := entity.NewInvestor("MARKET_MAKER")
+	for _, asset := range generator.Assets() {
+		for level := 1; level <= depth; level++ {
+			offset := float64(level) * 0.01
+			book.Process(entity.NewOrder(fmt.Sprintf("%s-BID%d", asset.ID, level), investor, asset, 100, 50-offset, enums.Buy))
+			book.Process(entity.NewOrder(fmt.Sprintf("%s-ASK%d", asset.ID, level), investor, asset, 100, 150+offset, enums.Sell))
+		}
+	}
+}
+
+func benchmarkOrderFlow(b *testing.B, config loadgen.Config, depth int, priceLevels bool) {
+	book := newBenchmarkBook(b)
+	book.PriceLevels = priceLevels
+	generator := newBenchmarkGenerator(b, config)
+	prefillBook(book, generator, depth)
+
+	actions := make([]loadgen.Action, b.N)
+	for i := range actions {
+		actions[i] = generator.Next()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, action := range actions {
+		loadgen.Apply(book, action)
+	}
+}
+
+func BenchmarkBookProcess(b *testing.B) {
+	config := loadgen.DefaultConfig()
+	config.CancelRatio = 0
+
+	b.Run("SingleAsset", func(b *testing.B) {
+		config := config
+		config.Assets = 1
+		benchmarkOrderFlow(b, config, 0, false)
+	})
+	b.Run("ManyAssets", func(b *testing.B) {
+		config := config
+		config.Assets = 1000
+		config.AssetSkew = 1.2
+		benchmarkOrderFlow(b, config, 0, false)
+	})
+	b.Run("DeepBook", func(b *testing.B) {
+		config := config
+		config.Assets = 1
+		benchmarkOrderFlow(b, config, 10000, false)
+	})
+	b.Run("HighCancelRatio", func(b *testing.B) {
+		config := config
+		config.CancelRatio = 0.9
+		benchmarkOrderFlow(b, config, 1000, false)
+	})
+}
+
+// BenchmarkBookSide compares the heap of orders with the price levels as the
+// sides of the book, on deep books where they differ the most.
+func BenchmarkBookSide(b *testing.B) {
+	config := loadgen.DefaultConfig()
+	config.Assets = 1
+
+	for _, side := range []struct {
+		name        string
+		priceLevels bool
+	}{{"OrderQueue", false}, {"PriceLevels", true}} {
+		b.Run(side.name, func(b *testing.B) {
+			b.Run("DeepBook", func(b *testing.B) {
+				config := config
+				config.CancelRatio = 0
+				benchmarkOrderFlow(b, config, 10000, side.priceLevels)
+			})
+			b.Run("HighCancelRatio", func(b *testing.B) {
+				config := config
+				config.CancelRatio = 0.9
+				benchmarkOrderFlow(b, config, 10000, side.priceLevels)
+			})
+			b.Run("CancelByID", func(b *testing.B) {
+				benchmarkCancelByID(b, 10000, side.priceLevels)
+			})
+			b.Run("Depth", func(b *testing.B) {
+				benchmarkDepth(b, 10000, side.priceLevels)
+			})
+		})
+	}
+}
+
+// benchmarkCancelByID cancels an order from the middle of a deep book by its
+// ID, then rests it again.
+func benchmarkCancelByID(b *testing.B, depth int, priceLevels bool) {
+	book := newBenchmarkBook(b)
+	book.PriceLevels = priceLevels
+	generator := newBenchmarkGenerator(b, loadgen.Config{Assets: 1, Investors: 1, MaxShares: 1, Seed: 1})
+	prefillBook(book, generator, depth)
+	asset := generator.Assets()[0]
+	orderID := fmt.Sprintf("%s-BID%d", asset.ID, depth/2)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		order, _ := book.CancelOrderByID(asset.ID, "MARKET_MAKER", orderID)
+		order.Status = enums.Open
+		book.Process(order)
+	}
+}
+
+// benchmarkDepth aggregates the depth of a deep book.
+func benchmarkDepth(b *testing.B, depth int, priceLevels bool) {
+	book := newBenchmarkBook(b)
+	book.PriceLevels = priceLevels
+	generator := newBenchmarkGenerator(b, loadgen.Config{Assets: 1, Investors: 1, MaxShares: 1, Seed: 1})
+	prefillBook(book, generator, depth)
+	asset := generator.Assets()[0]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		book.Depth(asset.ID)
+	}
+}
+
+// BenchmarkBookMatch measures a single match: each buy order takes the sell
+// order rested just before it.
+func BenchmarkBookMatch(b *testing.B) {
+	book := newBenchmarkBook(b)
+	asset := entity.NewAsset("ASSET1", "Asset 1", 0)
+	seller := entity.NewInvestor("SELLER")
+	buyer := entity.NewInvestor("BUYER")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		book.Process(entity.NewOrder("SELL", seller, asset, 10, 10, enums.Sell))
+		book.Process(entity.NewOrder("BUY", buyer, asset, 10, 10, enums.Buy))
+	}
+}
+
+// BenchmarkBookMatchPooled measures the same match as BenchmarkBookMatch on
+// the pooled hot path: pooled orders and transactions and sequence based
+// transaction IDs, which together make it allocation free.
+func BenchmarkBookMatchPooled(b *testing.B) {
+	book := newBenchmarkBook(b)
+	book.TransactionPool = entity.NewTransactionPool(16)
+	orders := entity.NewOrderPool(16)
+	asset := entity.NewAsset("ASSET1", "Asset 1", 0)
+	seller := entity.NewInvestor("SELLER")
+	buyer := entity.NewInvestor("BUYER")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sellOrder := orders.Get("SELL", seller, asset, 10, 10, enums.Sell)
+		buyOrder := orders.Get("BUY", buyer, asset, 10, 10, enums.Buy)
+		book.Process(sellOrder)
+		book.Process(buyOrder)
+		orders.Put(sellOrder)
+		orders.Put(buyOrder)
+	}
+}
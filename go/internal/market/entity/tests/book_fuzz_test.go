@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+const (
+	fuzzAssets    = 2
+	fuzzInvestors = 3
+	fuzzHoldings  = 1000
+)
+
+// runFuzzedOrderFlow decodes data into a sequence of orders and cancellations,
+// four bytes per step, runs them through a book and checks the matching
+// invariants after every step.
+func runFuzzedOrderFlow(t *testing.T, data []byte, selfTradePrevention enums.SelfTradePrevention, priceLevels bool) {
+	assets := []*entity.Asset{}
+	for i := 0; i < fuzzAssets; i++ {
+		assets = append(assets, entity.NewAsset(fmt.Sprintf("ASSET%d", i), "Asset", 0))
+	}
+	investors := []*entity.Investor{}
+	for i := 0; i < fuzzInvestors; i++ {
+		investor := entity.NewInvestor(fmt.Sprintf("INVESTOR%d", i))
+		for _, asset := range assets {
+			investor.AddAssetPosition(entity.NewInvestorAssetPosition(asset.ID, fuzzHoldings))
+		}
+		investors = append(investors, investor)
+	}
+
+	chanOut := make(chan *entity.Order, 1024)
+	done := make(chan struct{})
+	go func() {
+		for range chanOut {
+		}
+		close(done)
+	}()
+	defer func() {
+		close(chanOut)
+		<-done
+	}()
+
+	book := entity.NewBook(nil, chanOut, nil)
+	book.SelfTradePrevention = selfTradePrevention
+	book.PostOnlyReprice = true
+	book.PriceLevels = priceLevels
+
+	orders := []*entity.Order{}
+	checked := 0
+	for step := 0; step+4 <= len(data); step += 4 {
+		op, price, shares, flags := data[step], data[step+1], data[step+2], data[step+3]
+		asset := assets[int(op)%fuzzAssets]
+		investor := investors[int(op>>1)%fuzzInvestors]
+
+		if op>>4 == 0xF && len(orders) > 0 {
+			book.CancelOrder(orders[int(price)%len(orders)])
+		} else {
+			side := enums.Buy
+			if flags&1 == 1 {
+				side = enums.Sell
+			}
+			orderShares := 1 + int(shares)%20
+			orderPrice := 9 + float64(price%21)*0.1
+			id := fmt.Sprintf("ORDER%d", step/4)
+
+			order := entity.NewOrder(id, investor, asset, orderShares, orderPrice, side)
+			if flags&2 == 2 {
+				order = entity.NewIcebergOrder(id, investor, asset, orderShares, 1+int(flags>>4)%5, orderPrice, side)
+			}
+			order.PostOnly = flags&4 == 4
+			order.Hidden = flags&8 == 8
+
+			orders = append(orders, order)
+			book.Process(order)
+		}
+
+		if !checkMatchingInvariants(t, book, assets, investors, orders, book.Transactions[checked:]) {
+			t.Fatalf("invariants broken after step %d", step/4)
+		}
+		checked = len(book.Transactions)
+	}
+}
+
+// checkMatchingInvariants reports whether the book, the investors, the orders
+// and the transactions executed by the last step hold the invariants of the
+// matching engine, failing t for each broken one.
+func checkMatchingInvariants(t *testing.T, book *entity.Book, assets []*entity.Asset, investors []*entity.Investor, orders []*entity.Order, transactions []*entity.Transaction) bool {
+	broken := func(format string, args ...interface{}) bool {
+		t.Errorf(format, args...)
+		return false
+	}
+	ok := true
+
+	for _, asset := range assets {
+		shares := 0
+		for _, investor := range investors {
+			shares += investor.GetAssetPosition(asset.ID).TotalShares()
+		}
+		if shares != fuzzHoldings*fuzzInvestors {
+			ok = broken("Shares of %s should be conserved, got %d", asset.ID, shares)
+		}
+
+		bids := book.RestingOrders(asset.ID, enums.Buy)
+		asks := book.RestingOrders(asset.ID, enums.Sell)
+		if len(bids) > 0 && len(asks) > 0 && bids[0].Price >= asks[0].Price {
+			ok = broken("Book of %s should not be crossed, bid %v ask %v", asset.ID, bids[0].Price, asks[0].Price)
+		}
+
+		depth := book.Depth(asset.ID)
+		if expected := displayedPriceLevels(bids); !reflect.DeepEqual(depth.Bids, expected) {
+			ok = broken("Bids of %s should display %v, got %v", asset.ID, expected, depth.Bids)
+		}
+		if expected := displayedPriceLevels(asks); !reflect.DeepEqual(depth.Asks, expected) {
+			ok = broken("Asks of %s should display %v, got %v", asset.ID, expected, depth.Asks)
+		}
+	}
+
+	for _, order := range orders {
+		if order.PendingShares < 0 || order.PendingShares > order.Shares {
+			ok = broken("Order %s should have between 0 and %d pending shares, got %d", order.ID, order.Shares, order.PendingShares)
+		}
+		if order.Status == enums.Closed && order.PendingShares != 0 {
+			ok = broken("Closed order %s should have no pending shares, got %d", order.ID, order.PendingShares)
+		}
+	}
+
+	for _, transaction := range transactions {
+		if transaction.Shares <= 0 {
+			ok = broken("Transaction %s should trade shares, got %d", transaction.ID(), transaction.Shares)
+		}
+		if transaction.Price > transaction.BuyingOrder.Price || transaction.Price < transaction.SellingOrder.Price {
+			ok = broken("Transaction %s at %v should be within the buy limit %v and the sell limit %v",
+				transaction.ID(), transaction.Price, transaction.BuyingOrder.Price, transaction.SellingOrder.Price)
+		}
+	}
+	return ok
+}
+
+// displayedPriceLevels aggregates the shares displayed by resting orders
+// sorted in priority order, price by price.
+func displayedPriceLevels(orders []*entity.Order) []entity.PriceLevel {
+	levels := []entity.PriceLevel{}
+	for _, order := range orders {
+		shares := order.PendingShares
+		if order.IsIceberg() && order.VisibleShares < shares {
+			shares = order.VisibleShares
+		}
+		if order.Hidden || shares == 0 {
+			continue
+		}
+		if len(levels) == 0 || levels[len(levels)-1].Price != order.Price {
+			levels = append(levels, entity.PriceLevel{Price: order.Price})
+		}
+		levels[len(levels)-1].Shares += shares
+		levels[len(levels)-1].Orders++
+	}
+	return levels
+}
+
+func FuzzMatchingInvariants(f *testing.F) {
+	f.Add([]byte{0, 10, 5, 1, 2, 10, 5, 0}, uint8(0))
+	f.Add([]byte{0, 10, 19, 3, 2, 12, 7, 0, 4, 8, 3, 0, 0xF0, 0, 0, 0}, uint8(1))
+	f.Add([]byte{0, 5, 4, 1, 0, 6, 9, 0, 1, 5, 4, 5, 3, 4, 2, 12}, uint8(3))
+	f.Add([]byte{2, 20, 10, 1, 2, 0, 10, 0, 4, 10, 10, 1, 6, 10, 10, 4}, uint8(2))
+	f.Add([]byte{0, 10, 19, 3, 2, 12, 7, 0, 4, 8, 3, 0, 0xF0, 0, 0, 0}, uint8(4))
+
+	f.Fuzz(func(t *testing.T, data []byte, mode uint8) {
+		runFuzzedOrderFlow(t, data, enums.SelfTradePrevention(mode%4), mode&4 != 0)
+	})
+}
+
+// TestMatchingInvariantsRandomized runs random order flows through the
+// invariants on every test run, not only when fuzzing.
+func TestMatchingInvariantsRandomized(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for run := 0; run < 50; run++ {
+		data := make([]byte, 4*200)
+		random.Read(data)
+		mode := enums.SelfTradePrevention(run % 4)
+		priceLevels := run%8 >= 4
+		t.Run(fmt.Sprintf("%d-%s-levels=%t", run, mode, priceLevels), func(t *testing.T) {
+			runFuzzedOrderFlow(t, data, mode, priceLevels)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package entity
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/medina325/stock_market/go/internal/market/entity"
@@ -299,3 +300,122 @@ func TestNoMatchingOrders(t *testing.T) {
 	assert.Equal(0, buyOrder.TransactionsCount(), "Buy order should have 0 transactions")
 	assert.Equal(0, sellOrder.TransactionsCount(), "Sell order should have 0 transaction")
 }
+
+func TestIncomingOrderSweepsPriceLevels(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 200)
+
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 15))
+
+	chanOut := make(chan *entity.Order, 20)
+	wg := sync.WaitGroup{}
+	book := entity.NewBook(nil, chanOut, &wg)
+
+	sellOrder1 := entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 10, enums.Sell)
+	sellOrder2 := entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 11, enums.Sell)
+	sellOrder3 := entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 12, enums.Sell)
+	book.Process(sellOrder1)
+	book.Process(sellOrder2)
+	book.Process(sellOrder3)
+
+	// One transaction, and one call to Wg.Done, per resting order traded.
+	wg.Add(2)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 12, 11, enums.Buy)
+	book.Process(buyOrder)
+	wg.Wait()
+
+	assert := assert.New(t)
+
+	assert.Equal(2, buyOrder.TransactionsCount(), "Buy order should trade with every crossing price level")
+	assert.Equal(10.0, buyOrder.Transactions[0].Price, "Best price level should trade first, at its price")
+	assert.Equal(11.0, buyOrder.Transactions[1].Price, "Next price level should trade next, at its price")
+	assert.Equal(enums.Closed, sellOrder1.Status)
+	assert.Equal(enums.Closed, sellOrder2.Status)
+	assert.Equal(5, sellOrder3.PendingShares, "Price level above the limit should not trade")
+
+	assert.Equal(enums.Open, buyOrder.Status)
+	assert.Equal(2, buyOrder.PendingShares)
+	assert.Equal([]entity.PriceLevel{{Price: 11, Shares: 2, Orders: 1}}, book.Depth(a.ID).Bids, "What is left of the buy order should rest after matching")
+}
+
+func TestOrdersMatchInPriceThenArrivalOrder(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 200)
+
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 12))
+
+	chanOut := make(chan *entity.Order, 20)
+	book := entity.NewBook(nil, chanOut, nil)
+
+	firstBuyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10, enums.Buy)
+	secondBuyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10, enums.Buy)
+	thirdBuyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10, enums.Buy)
+	bestBuyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 11, enums.Buy)
+	book.Process(firstBuyOrder)
+	book.Process(secondBuyOrder)
+	book.Process(thirdBuyOrder)
+	book.Process(bestBuyOrder)
+
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 7, 9, enums.Sell))
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Closed, bestBuyOrder.Status, "Highest bid should trade first even though it arrived last")
+	assert.Equal(3, firstBuyOrder.PendingShares, "First order at a price should trade before the others")
+	assert.Equal(5, secondBuyOrder.PendingShares)
+
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 10, enums.Sell))
+
+	assert.Equal(enums.Closed, firstBuyOrder.Status)
+	assert.Equal(3, secondBuyOrder.PendingShares, "Second order at a price should trade next")
+	assert.Equal(5, thirdBuyOrder.PendingShares, "Last order at a price should trade last")
+}
+
+func TestConsumersReadTheBookWhileUpdatesAreSent(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order)
+	statusChanOut := make(chan *entity.AssetStatus)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.StatusChanOut = statusChanOut
+
+	// Unbuffered consumers reading the book for every update they receive.
+	depths := make(chan *entity.BookDepth, 10)
+	go func() {
+		for range chanOut {
+			depths <- book.Depth(a.ID)
+		}
+	}()
+	phases := make(chan enums.TradingPhase, 10)
+	go func() {
+		for range statusChanOut {
+			phases <- book.Phase(a.ID)
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell))
+		book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 5, enums.Buy))
+		book.Halt(a.ID, "news pending")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Book should not hold its lock while its updates are received")
+	}
+
+	assert := assert.New(t)
+
+	// Both orders of the match are published.
+	<-depths
+	assert.Equal([]entity.PriceLevel{{Price: 5, Shares: 6, Orders: 1}}, (<-depths).Asks, "Consumers should read the book after the match")
+	assert.Equal(enums.Halted, <-phases)
+}
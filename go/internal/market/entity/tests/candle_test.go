@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandleAggregation(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	seller := entity.NewInvestor(uuid.NewString())
+	buyer := entity.NewInvestor(uuid.NewString())
+	start := time.Date(2023, 9, 4, 10, 0, 0, 0, time.UTC)
+
+	aggregator := entity.NewCandleAggregator(time.Minute, time.Hour)
+	aggregator.AddTransaction(newTestTransaction(seller, buyer, a, 10, 10, start.Add(5*time.Second)))
+	aggregator.AddTransaction(newTestTransaction(seller, buyer, a, 30, 12, start.Add(20*time.Second)))
+	aggregator.AddTransaction(newTestTransaction(seller, buyer, a, 10, 9, start.Add(59*time.Second)))
+	aggregator.AddTransaction(newTestTransaction(seller, buyer, a, 5, 11, start.Add(3*time.Minute)))
+	// Out of order transactions end up in the right candle.
+	aggregator.AddTransaction(newTestTransaction(seller, buyer, a, 5, 8, start.Add(10*time.Second)))
+
+	assert := assert.New(t)
+
+	bars := aggregator.Bars(a.ID, time.Minute, start, start.Add(time.Hour), false)
+	assert.Len(bars, 2, "There should be a candle for each minute with transactions")
+
+	first := bars[0]
+	assert.Equal(start, first.Start)
+	assert.Equal(10.0, first.Open)
+	assert.Equal(12.0, first.High)
+	assert.Equal(8.0, first.Low)
+	assert.Equal(9.0, first.Close, "Close should be the latest transaction")
+	assert.Equal(55, first.Volume)
+	assert.InDelta(590.0/55.0, first.VWAP, 1e-9)
+	assert.Equal(4, first.Trades)
+
+	hourly := aggregator.Bars(a.ID, time.Hour, start, start.Add(time.Hour), false)
+	assert.Len(hourly, 1, "There should be a single hourly candle")
+	assert.Equal(60, hourly[0].Volume)
+	assert.Equal(11.0, hourly[0].Close)
+
+	filled := aggregator.Bars(a.ID, time.Minute, start, start.Add(time.Hour), true)
+	assert.Len(filled, 4, "Gaps between candles should be filled")
+	assert.Equal(start.Add(time.Minute), filled[1].Start)
+	assert.Equal(9.0, filled[1].Open, "Gap candles should be flat at the previous close")
+	assert.Equal(9.0, filled[2].Close, "Gap candles should be flat at the previous close")
+	assert.Equal(0, filled[2].Volume, "Gap candles should have no volume")
+	assert.Equal(11.0, filled[3].Close)
+
+	ranged := aggregator.Bars(a.ID, time.Minute, start.Add(time.Minute), start.Add(3*time.Minute), true)
+	assert.Len(ranged, 2, "Only candles starting within the range should be returned")
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClearingHouseSettlement(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	seller := entity.NewInvestor(uuid.NewString())
+	seller.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyer := entity.NewInvestor(uuid.NewString())
+
+	// 2023-09-08 is a Friday.
+	friday := time.Date(2023, 9, 8, 15, 0, 0, 0, time.UTC)
+	clearingHouse := entity.NewClearingHouse(2)
+	clearingHouse.Clear(newTestTransaction(seller, buyer, a, 4, 10, friday))
+	clearingHouse.Clear(newTestTransaction(seller, buyer, a, 3, 10, friday.Add(time.Hour)))
+	clearingHouse.Clear(newTestTransaction(buyer, seller, a, 2, 11, friday.Add(2*time.Hour)))
+
+	assert := assert.New(t)
+
+	obligations := clearingHouse.Obligations()
+	assert.Len(obligations, 2, "Obligations should be netted per investor, asset and day")
+	assert.Equal(buyer, obligations[0].Investor)
+	assert.Equal(5, obligations[0].Shares, "Buyer should receive 7 - 2 shares")
+	assert.InDelta(-48.0, obligations[0].Amount, 1e-9, "Buyer should pay 70 - 22")
+	assert.Equal(seller, obligations[1].Investor)
+	assert.Equal(-5, obligations[1].Shares, "Seller should deliver 7 - 2 shares")
+	assert.InDelta(48.0, obligations[1].Amount, 1e-9, "Seller should receive 70 - 22")
+	assert.Equal(time.Date(2023, 9, 12, 0, 0, 0, 0, time.UTC), obligations[0].SettlementDate, "T+2 should skip the weekend")
+
+	assert.Equal(10, seller.GetAssetPosition(a.ID).Shares, "Seller settled position should not change before settlement")
+	assert.Equal(-5, seller.GetAssetPosition(a.ID).UnsettledShares, "Seller should have 5 shares to deliver")
+	assert.Equal(0, buyer.GetAssetPosition(a.ID).Shares, "Buyer settled position should not change before settlement")
+	assert.Equal(5, buyer.GetAssetPosition(a.ID).UnsettledShares, "Buyer should have 5 shares to receive")
+
+	assert.Empty(clearingHouse.Settle(friday.AddDate(0, 0, 3)), "Nothing should settle on T+1")
+
+	settled := clearingHouse.Settle(friday.AddDate(0, 0, 4))
+	assert.Len(settled, 2, "Both obligations should settle on T+2")
+	assert.Equal(enums.Settled, obligations[0].Status)
+	assert.Equal(enums.Settled, obligations[1].Status)
+	assert.Equal(5, seller.GetAssetPosition(a.ID).Shares, "Seller should have delivered 5 shares")
+	assert.Equal(0, seller.GetAssetPosition(a.ID).UnsettledShares)
+	assert.Equal(5, buyer.GetAssetPosition(a.ID).Shares, "Buyer should have received 5 shares")
+	assert.Equal(0, buyer.GetAssetPosition(a.ID).UnsettledShares)
+}
+
+func TestClearingHouseFailedSettlement(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	seller := entity.NewInvestor(uuid.NewString())
+	seller.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 2))
+	buyer := entity.NewInvestor(uuid.NewString())
+
+	monday := time.Date(2023, 9, 4, 15, 0, 0, 0, time.UTC)
+	clearingHouse := entity.NewClearingHouse(1)
+	clearingHouse.Clear(newTestTransaction(seller, buyer, a, 5, 10, monday))
+
+	settled := clearingHouse.Settle(monday.AddDate(0, 0, 1))
+
+	assert := assert.New(t)
+
+	assert.Len(settled, 2)
+	assert.Equal(enums.Settled, settled[0].Status, "Receipt should settle")
+	assert.Equal(enums.SettlementFailed, settled[1].Status, "Delivery should fail without enough holdings")
+	assert.ErrorIs(settled[1].FailReason, entity.ErrInsufficientHoldings)
+	assert.Equal(2, seller.GetAssetPosition(a.ID).Shares, "Failed delivery should not move shares")
+
+	seller.UpdateAssetPosition(a.ID, 3)
+	retried := clearingHouse.Settle(monday.AddDate(0, 0, 2))
+
+	assert.Len(retried, 1, "Failed delivery should be retried")
+	assert.Equal(enums.Settled, retried[0].Status, "Delivery should settle once holdings are enough")
+	assert.Equal(0, seller.GetAssetPosition(a.ID).Shares)
+}
@@ -0,0 +1,183 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoodTillDateOrdersExpire(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	now := time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC)
+	clock := entity.NewFakeClock(now)
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+
+	laterOrder := entity.NewGoodTillDateOrder(uuid.NewString(), investor, a, 10, 4, enums.Buy, now.Add(time.Hour))
+	soonerOrder := entity.NewGoodTillDateOrder(uuid.NewString(), investor, a, 10, 5, enums.Buy, now.Add(time.Minute))
+	book.Process(laterOrder)
+	book.Process(soonerOrder)
+
+	assert := assert.New(t)
+
+	nextExpiry, ok := book.NextExpiry()
+	assert.True(ok, "There should be an order waiting to expire")
+	assert.Equal(now.Add(time.Minute), nextExpiry, "The sooner order should expire first")
+
+	clock.Set(nextExpiry.Add(-time.Nanosecond))
+	book.Tick()
+
+	assert.Empty(chanOut, "Nothing should expire before the expiry")
+	assert.Len(book.Depth(a.ID).Bids, 2, "Both orders should still be in the book")
+
+	clock.Set(nextExpiry)
+	book.Tick()
+
+	assert.Equal(soonerOrder, <-chanOut, "Expired order should be published")
+	assert.Equal(enums.Expired, soonerOrder.Status, "Order should expire at its expiry")
+	assert.Equal(enums.Open, laterOrder.Status, "Later order should still be open")
+	assert.Equal([]entity.PriceLevel{{Price: 4, Shares: 10, Orders: 1}}, book.Depth(a.ID).Bids, "Expired order should leave the depth")
+
+	nextExpiry, _ = book.NextExpiry()
+	assert.Equal(now.Add(time.Hour), nextExpiry, "The later order should expire next")
+}
+
+func TestExpiredOrderOnArrival(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	now := time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC)
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = entity.NewFakeClock(now)
+
+	order := entity.NewGoodTillDateOrder(uuid.NewString(), investor, a, 10, 4, enums.Buy, now.Add(-time.Second))
+	book.Process(order)
+
+	assert := assert.New(t)
+
+	assert.Equal(order, <-chanOut, "Expired order should be published")
+	assert.Equal(enums.Expired, order.Status, "Order should expire on arrival")
+	assert.Empty(book.Depth(a.ID).Bids, "Expired order should not rest in the book")
+	_, ok := book.NextExpiry()
+	assert.False(ok, "No order should be waiting to expire")
+}
+
+func TestExpiredOrderDoesNotTradeBeforeTick(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	now := time.Date(2023, 9, 4, 8, 0, 0, 0, time.UTC)
+	clock := entity.NewFakeClock(now)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.Clock = clock
+
+	buyOrder := entity.NewGoodTillDateOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy, now.Add(time.Hour))
+	book.Process(buyOrder)
+
+	clock.Set(now.Add(2 * time.Hour))
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+	book.Process(sellOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Expired, buyOrder.Status, "Order should expire before the next order is matched")
+	assert.Equal(0, buyOrder.TransactionsCount(), "Expired order should not trade")
+	assert.Equal(enums.Open, sellOrder.Status)
+	assert.Equal([]entity.PriceLevel{{Price: 5, Shares: 10, Orders: 1}}, book.Depth(a.ID).Asks, "Incoming order should rest")
+}
+
+func TestQueuedOrderExpiredOvernightDoesNotTradeAtOpen(t *testing.T) {
+	// 2023-09-04 is a Monday.
+	monday := time.Date(2023, 9, 4, 0, 0, 0, 0, time.UTC)
+
+	for _, openedBy := range []string{"order", "tick"} {
+		t.Run(openedBy, func(t *testing.T) {
+			a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+			sellInvestor := entity.NewInvestor(uuid.NewString())
+			sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+			buyInvestor := entity.NewInvestor(uuid.NewString())
+
+			chanOut := make(chan *entity.Order, 10)
+			clock := entity.NewFakeClock(monday.Add(18 * time.Hour))
+			book := entity.NewBook(nil, chanOut, nil)
+			book.Clock = clock
+			book.Schedules[a.ID] = entity.NewTradingSchedule(time.UTC, 10*time.Hour, 17*time.Hour)
+			book.QueueOutsideHours = true
+
+			tuesday := monday.Add(24 * time.Hour)
+			buyOrder := entity.NewGoodTillDateOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy, tuesday.Add(9*time.Hour))
+			book.Process(buyOrder)
+			sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+
+			if openedBy == "tick" {
+				book.Process(sellOrder)
+				clock.Set(tuesday.Add(10 * time.Hour))
+				book.Tick()
+			} else {
+				clock.Set(tuesday.Add(10 * time.Hour))
+				book.Process(sellOrder)
+			}
+
+			assert := assert.New(t)
+
+			assert.Equal(enums.Expired, buyOrder.Status, "Queued order should expire before the open")
+			assert.Equal(0, buyOrder.TransactionsCount(), "Expired order should not trade at the open")
+			assert.Equal(enums.Open, sellOrder.Status)
+		})
+	}
+}
+
+func TestOrdersExpiringTogetherExpireInArrivalOrder(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 30))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	now := time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC)
+	clock := entity.NewFakeClock(now)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.Clock = clock
+
+	expiresAt := now.Add(time.Hour)
+	icebergOrder := entity.NewIcebergOrder(uuid.NewString(), sellInvestor, a, 10, 5, 5, enums.Sell)
+	icebergOrder.TimeInForce = enums.GoodTillDate
+	icebergOrder.ExpiresAt = expiresAt
+	firstOrder := entity.NewGoodTillDateOrder(uuid.NewString(), sellInvestor, a, 10, 6, enums.Sell, expiresAt)
+	secondOrder := entity.NewGoodTillDateOrder(uuid.NewString(), sellInvestor, a, 10, 6, enums.Sell, expiresAt)
+	book.Process(icebergOrder)
+	book.Process(firstOrder)
+	book.Process(secondOrder)
+
+	// Consuming the displayed slice sends the iceberg order to the back of
+	// the queue, after both other orders.
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 5, enums.Buy))
+	for len(chanOut) > 0 {
+		<-chanOut
+	}
+
+	clock.Set(expiresAt)
+	book.Tick()
+
+	assert := assert.New(t)
+
+	assert.Equal(firstOrder, <-chanOut, "First order to arrive should expire first")
+	assert.Equal(secondOrder, <-chanOut)
+	assert.Equal(icebergOrder, <-chanOut, "Replenished iceberg order should expire last")
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeScheduleRate(t *testing.T) {
+	schedule := entity.NewFeeSchedule(entity.FeeRate{Maker: 0.001, Taker: 0.002})
+	schedule.Assets["asset"] = entity.FeeRate{Maker: 0.003, Taker: 0.004}
+	schedule.Tiers["vip"] = entity.FeeRate{Maker: 0, Taker: 0.001}
+	schedule.SetAssetTierRate("asset", "vip", entity.FeeRate{Maker: -0.0005, Taker: 0.0005})
+
+	assert := assert.New(t)
+
+	assert.Equal(entity.FeeRate{Maker: 0.001, Taker: 0.002}, schedule.Rate("other", ""), "Default rate should be used")
+	assert.Equal(entity.FeeRate{Maker: 0.003, Taker: 0.004}, schedule.Rate("asset", ""), "Asset rate should be used")
+	assert.Equal(entity.FeeRate{Maker: 0, Taker: 0.001}, schedule.Rate("other", "vip"), "Tier rate should be used")
+	assert.Equal(entity.FeeRate{Maker: -0.0005, Taker: 0.0005}, schedule.Rate("asset", "vip"), "Asset tier rate should be used")
+
+	var noSchedule *entity.FeeSchedule
+	assert.Equal(entity.FeeRate{}, noSchedule.Rate("asset", "vip"), "No fees should be charged without a schedule")
+}
+
+func TestTransactionFees(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	buyInvestor.Tier = "vip"
+
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	book.FeeSchedule = entity.NewFeeSchedule(entity.FeeRate{Maker: 0.01, Taker: 0.02})
+	book.FeeSchedule.Tiers["vip"] = entity.FeeRate{Maker: 0, Taker: 0.005}
+	go book.Trade()
+
+	wg.Add(1)
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell)
+	chanIn <- sellOrder
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 10, enums.Buy)
+	chanIn <- buyOrder
+	wg.Wait()
+
+	<-chanOut
+	<-chanOut
+
+	assert := assert.New(t)
+
+	transaction := buyOrder.Transactions[0]
+	assert.Equal(enums.Buy, transaction.Aggressor, "The buy order should be the aggressor")
+	assert.InDelta(100.0, transaction.Total, 1e-9)
+	assert.InDelta(0.5, transaction.BuyerFee, 1e-9, "Buyer should pay the vip taker fee")
+	assert.InDelta(1.0, transaction.SellerFee, 1e-9, "Seller should pay the default maker fee")
+	assert.InDelta(100.5, transaction.BuyerNet, 1e-9, "Buyer should pay the total plus fees")
+	assert.InDelta(99.0, transaction.SellerNet, 1e-9, "Seller should receive the total minus fees")
+}
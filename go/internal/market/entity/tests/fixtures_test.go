@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// newTestTransaction creates a transaction of shares of an asset sold by
+// seller to buyer at price, outside of any book.
+func newTestTransaction(seller, buyer *entity.Investor, a *entity.Asset, shares int, price float64, dateTime time.Time) *entity.Transaction {
+	sellOrder := entity.NewOrder(uuid.NewString(), seller, a, shares, price, enums.Sell)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyer, a, shares, price, enums.Buy)
+
+	return entity.NewTransaction(uuid.NewString(), sellOrder, buyOrder, shares, price, enums.Buy, dateTime)
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIcebergOrder(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	icebergInvestor := entity.NewInvestor(uuid.NewString())
+	icebergInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 30))
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	go book.Trade()
+
+	icebergOrder := entity.NewIcebergOrder(uuid.NewString(), icebergInvestor, a, 30, 10, 5, enums.Sell)
+	chanIn <- icebergOrder
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+	chanIn <- sellOrder
+
+	assert := assert.New(t)
+
+	assert.Eventually(func() bool {
+		asks := book.Depth(a.ID).Asks
+		return len(asks) == 1 && asks[0].Shares == 20
+	}, time.Second, time.Millisecond, "Only the displayed slice of the iceberg order should be in the depth")
+
+	wg.Add(2)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 15, 5, enums.Buy)
+	chanIn <- buyOrder
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		<-chanOut
+	}
+
+	assert.Equal(enums.Closed, buyOrder.Status, "Buy order should be closed")
+	assert.Equal(2, buyOrder.TransactionsCount(), "Buy order should have 2 transactions")
+	assert.Equal(icebergOrder, buyOrder.Transactions[0].SellingOrder, "The displayed slice should be matched first")
+	assert.Equal(10, buyOrder.Transactions[0].Shares, "Only the displayed slice should be matched")
+	assert.Equal(sellOrder, buyOrder.Transactions[1].SellingOrder, "The replenished slice should lose time priority")
+	assert.Equal(5, buyOrder.Transactions[1].Shares, "The rest should be matched against the other order")
+
+	assert.Equal(20, icebergOrder.PendingShares, "Iceberg order should have 20 pending shares")
+	assert.Equal(10, icebergOrder.VisibleShares, "Iceberg order should show a new slice of 10 shares")
+	assert.Equal(enums.Open, icebergOrder.Status, "Iceberg order should still be open")
+
+	assert.Eventually(func() bool {
+		asks := book.Depth(a.ID).Asks
+		return len(asks) == 1 && asks[0].Shares == 15 && asks[0].Orders == 2
+	}, time.Second, time.Millisecond, "Depth should show the replenished slice and the rest of the other order")
+}
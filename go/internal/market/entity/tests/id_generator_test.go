@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceIDGenerator(t *testing.T) {
+	generator := entity.NewSequenceIDGenerator("T")
+
+	assert := assert.New(t)
+
+	assert.Equal("T1", generator.NewID())
+	assert.Equal("T2", generator.NewID())
+	assert.Equal("T3", generator.NewID())
+}
+
+func TestTransactionsUseBookIDsAndClock(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	start := time.Date(2023, 9, 8, 10, 0, 0, 0, time.UTC)
+	clock := entity.NewFakeClock(start)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+	book.IDGenerator = entity.NewSequenceIDGenerator("T")
+
+	wg.Add(2)
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 10, enums.Buy))
+	clock.Advance(time.Minute)
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 6, 10, enums.Buy))
+	wg.Wait()
+
+	assert := assert.New(t)
+
+	assert.Len(book.Transactions, 2)
+	assert.Equal("T1", book.Transactions[0].ID())
+	assert.Equal(start, book.Transactions[0].DateTime)
+	assert.Equal("T2", book.Transactions[1].ID())
+	assert.Equal(start.Add(time.Minute), book.Transactions[1].DateTime)
+}
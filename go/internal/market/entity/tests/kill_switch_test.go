@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKillSwitch(t *testing.T) {
+	asset1 := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	asset2 := entity.NewAsset(uuid.NewString(), "Asset 2", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	otherInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.RiskGate = entity.NewRiskGate(book.Clock)
+	book.RiskGate.SetLimits(investor.ID, entity.RiskLimits{MaxOpenOrders: 2})
+
+	order1 := entity.NewOrder(uuid.NewString(), investor, asset1, 10, 5, enums.Buy)
+	order2 := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	otherOrder := entity.NewOrder(uuid.NewString(), otherInvestor, asset1, 10, 4, enums.Buy)
+	book.Process(order1)
+	book.Process(order2)
+	book.Process(otherOrder)
+
+	cancelled := book.KillSwitch(investor.ID, "")
+
+	assert := assert.New(t)
+
+	assert.ElementsMatch([]*entity.Order{order1, order2}, cancelled, "Every order of the investor should be cancelled")
+	assert.ElementsMatch([]*entity.Order{order1, order2}, []*entity.Order{<-chanOut, <-chanOut}, "Cancellations should be published")
+	assert.Equal(enums.Cancelled, order1.Status, "Order 1 should be cancelled")
+	assert.Equal(enums.Cancelled, order2.Status, "Order 2 should be cancelled")
+	assert.Equal(enums.Open, otherOrder.Status, "Orders of other investors should not be cancelled")
+
+	blockedOrder := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	book.Process(blockedOrder)
+
+	assert.Equal(blockedOrder, <-chanOut, "Rejected order should be published")
+	assert.ErrorIs(blockedOrder.RejectReason, entity.ErrInvestorBlocked)
+
+	book.ReleaseKillSwitch(investor.ID)
+
+	newOrder1 := entity.NewOrder(uuid.NewString(), investor, asset1, 10, 5, enums.Buy)
+	newOrder2 := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	book.Process(newOrder1)
+	book.Process(newOrder2)
+
+	assert.Equal(enums.Open, newOrder1.Status, "Investor should trade again once released")
+	assert.Equal(enums.Open, newOrder2.Status, "Cancelled orders should not count against the open orders limit")
+	assert.Empty(chanOut, "Nothing should be rejected")
+}
+
+func TestKillSwitchForSingleAsset(t *testing.T) {
+	asset1 := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	asset2 := entity.NewAsset(uuid.NewString(), "Asset 2", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+
+	order1 := entity.NewOrder(uuid.NewString(), investor, asset1, 10, 5, enums.Buy)
+	order2 := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	book.Process(order1)
+	book.Process(order2)
+
+	cancelled := book.KillSwitch(investor.ID, asset1.ID)
+
+	assert := assert.New(t)
+
+	assert.Equal([]*entity.Order{order1}, cancelled, "Only the orders of asset 1 should be cancelled")
+	assert.Equal(enums.Open, order2.Status, "Orders of asset 2 should stay open")
+	<-chanOut
+
+	order3 := entity.NewOrder(uuid.NewString(), investor, asset2, 10, 5, enums.Buy)
+	book.Process(order3)
+
+	assert.Equal(enums.Open, order3.Status, "Investor should still trade asset 2")
+}
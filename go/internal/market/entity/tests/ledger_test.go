@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerPostsTransactions(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	opening := time.Date(2023, 9, 8, 10, 0, 0, 0, time.UTC)
+	ledger := entity.NewLedger()
+	assert := assert.New(t)
+	assert.NoError(ledger.OpenPositions(sellInvestor, opening))
+	assert.NoError(ledger.Deposit("deposit", opening, buyInvestor.ID, entity.CashInstrument, 1000))
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Ledger = ledger
+	book.FeeSchedule = entity.NewFeeSchedule(entity.FeeRate{Maker: 0.001, Taker: 0.002})
+
+	wg.Add(2)
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 6, 10, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 10, enums.Buy))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 2, 10, enums.Buy))
+	wg.Wait()
+
+	assert.Len(ledger.Entries(), 2+2+2*8, "Each transaction should post eight entries")
+	assert.Equal(4.0, ledger.Balance(sellInvestor.ID, a.ID))
+	assert.Equal(6.0, ledger.Balance(buyInvestor.ID, a.ID))
+	assert.InDelta(1000-60-60*0.002, ledger.Balance(buyInvestor.ID, entity.CashInstrument), 1e-9, "Buyer should pay the total plus the taker fee")
+	assert.InDelta(60-60*0.001, ledger.Balance(sellInvestor.ID, entity.CashInstrument), 1e-9, "Seller should receive the total minus the maker fee")
+	assert.InDelta(60*0.003, ledger.Balance(entity.HouseAccountID, entity.CashInstrument), 1e-9, "House should collect both fees")
+
+	cash := 0.0
+	for _, entry := range ledger.Entries() {
+		if entry.Instrument == entity.CashInstrument {
+			cash += entry.Amount
+		}
+	}
+	assert.InDelta(0.0, cash, 1e-9, "Ledger should balance")
+
+	assert.Empty(ledger.Reconcile(sellInvestor, buyInvestor), "Positions should equal the ledger")
+
+	buyInvestor.UpdateAssetPosition(a.ID, 1)
+	breaks := ledger.Reconcile(sellInvestor, buyInvestor)
+	assert.Len(breaks, 1, "Movements outside the ledger should break the reconciliation")
+	assert.Equal(buyInvestor.ID, breaks[0].InvestorID)
+	assert.Equal(7, breaks[0].PositionShares)
+	assert.Equal(6.0, breaks[0].LedgerBalance)
+}
+
+func TestLedgerRejectsUnbalancedJournal(t *testing.T) {
+	ledger := entity.NewLedger()
+
+	err := ledger.Post("journal", time.Now(), "unbalanced",
+		entity.LedgerEntry{AccountID: "a", Instrument: entity.CashInstrument, Amount: -10},
+		entity.LedgerEntry{AccountID: "b", Instrument: entity.CashInstrument, Amount: 9},
+	)
+
+	assert := assert.New(t)
+
+	assert.ErrorIs(err, entity.ErrUnbalancedJournal)
+	assert.Empty(ledger.Entries(), "Unbalanced journal should not be posted")
+}
+
+func TestLedgerPostsLargeTransactionsWithFees(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+
+	const trades = 200
+	const shares = 100000
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, trades*shares))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	ledger := entity.NewLedger()
+	assert := assert.New(t)
+	assert.NoError(ledger.OpenPositions(sellInvestor, time.Date(2023, 9, 8, 10, 0, 0, 0, time.UTC)))
+
+	chanOut := make(chan *entity.Order, 2*trades)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.Ledger = ledger
+	book.FeeSchedule = entity.NewFeeSchedule(entity.FeeRate{Maker: 0.0003, Taker: 0.0007})
+
+	for i := 0; i < trades; i++ {
+		price := 1234.57 + float64(i)*0.01
+		book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, shares, price, enums.Sell))
+		book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, shares, price, enums.Buy))
+	}
+
+	assert.Len(ledger.Entries(), 2+trades*8, "Every transaction should be posted")
+	assert.Equal(float64(trades*shares), ledger.Balance(buyInvestor.ID, a.ID))
+	assert.Empty(ledger.Reconcile(sellInvestor, buyInvestor), "Positions should equal the ledger")
+
+	cash := ledger.Balance(buyInvestor.ID, entity.CashInstrument) +
+		ledger.Balance(sellInvestor.ID, entity.CashInstrument) +
+		ledger.Balance(entity.HouseAccountID, entity.CashInstrument)
+	assert.InDelta(0.0, cash, 1e-3, "Cash should balance across the accounts")
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookLogsOrderFlow(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	var logs bytes.Buffer
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Logger = slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell)
+	sellOrder.TraceID = "sell-trace"
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 10, enums.Buy)
+	buyOrder.TraceID = "buy-trace"
+
+	wg.Add(1)
+	book.Process(sellOrder)
+	book.Process(buyOrder)
+	wg.Wait()
+
+	assert := assert.New(t)
+
+	assert.Equal("buy-trace", book.Transactions[0].TraceID, "Transaction should carry the trace ID of the aggressor")
+
+	messages := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(json.Unmarshal([]byte(line), &entry))
+		assert.Equal("DEBUG", entry["level"])
+		assert.Equal(a.ID, entry["asset_id"])
+		assert.Contains([]interface{}{"sell-trace", "buy-trace"}, entry["trace_id"])
+
+		message := entry["msg"].(string)
+		messages = append(messages, message)
+		if message == "transaction executed" {
+			assert.Equal(book.Transactions[0].ID(), entry["transaction_id"])
+			assert.Equal(sellOrder.ID, entry["sell_order_id"])
+			assert.Equal(float64(1), entry["sell_sequence"])
+			assert.Equal(float64(2), entry["buy_sequence"])
+		} else if message == "order received" {
+			assert.NotEmpty(entry["order_id"])
+			assert.NotContains(entry, "sequence", "Order should have no arrival number when received")
+		} else {
+			assert.NotEmpty(entry["order_id"])
+			assert.NotNil(entry["sequence"])
+		}
+	}
+
+	assert.Equal([]string{
+		"order received", "order accepted", "order resting",
+		"order received", "order accepted", "transaction executed",
+		"order update published", "order update published",
+	}, messages)
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPooledBookReleasesTransactions(t *testing.T) {
+	asset := entity.NewAsset("ASSET1", "Asset 1", 0)
+	seller := entity.NewInvestor("SELLER")
+	buyer := entity.NewInvestor("BUYER")
+
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.TransactionPool = entity.NewTransactionPool(1)
+	book.IDGenerator = entity.NewSequenceIDGenerator("T")
+	book.Candles = entity.NewCandleAggregator(time.Minute)
+
+	sellOrder := entity.NewOrder("SELL", seller, asset, 10, 10, enums.Sell)
+	buyOrder := entity.NewOrder("BUY", buyer, asset, 10, 10, enums.Buy)
+	book.Process(sellOrder)
+	book.Process(buyOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Closed, sellOrder.Status)
+	assert.Equal(enums.Closed, buyOrder.Status)
+	assert.Equal(10, buyer.GetAssetPosition(asset.ID).Shares)
+	assert.Empty(book.Transactions, "Pooled transactions should not be kept by the book")
+	assert.Empty(buyOrder.Transactions, "Pooled transactions should not be kept by the orders")
+
+	bars := book.Candles.Bars(asset.ID, time.Minute, time.Time{}, time.Now().Add(time.Hour), false)
+	assert.Len(bars, 1, "Hooks should still see pooled transactions")
+	assert.Equal(10, bars[0].Volume)
+}
+
+func TestPooledGoodTillDateOrderLeavesExpiries(t *testing.T) {
+	asset := entity.NewAsset("ASSET1", "Asset 1", 0)
+	investor := entity.NewInvestor("INVESTOR")
+
+	now := time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC)
+	clock := entity.NewFakeClock(now)
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.Clock = clock
+	orders := entity.NewOrderPool(0)
+
+	cancelled := orders.Get("GTD1", investor, asset, 10, 5, enums.Buy)
+	cancelled.TimeInForce = enums.GoodTillDate
+	cancelled.ExpiresAt = now.Add(time.Minute)
+	book.Process(cancelled)
+	book.CancelOrder(cancelled)
+	orders.Put(cancelled)
+
+	// The recycled order rests as a good-till-cancelled order.
+	recycled := orders.Get("GTC1", investor, asset, 10, 4, enums.Buy)
+	book.Process(recycled)
+
+	expiring := entity.NewGoodTillDateOrder("GTD2", investor, asset, 10, 3, enums.Buy, now.Add(time.Hour))
+	book.Process(expiring)
+
+	assert := assert.New(t)
+	assert.Same(cancelled, recycled, "The pool should hand the cancelled order out again")
+
+	nextExpiry, ok := book.NextExpiry()
+	assert.True(ok, "There should be an order waiting to expire")
+	assert.Equal(now.Add(time.Hour), nextExpiry, "The cancelled order should no longer be tracked")
+
+	clock.Set(nextExpiry)
+	book.Tick()
+
+	assert.Equal(enums.Expired, expiring.Status, "Good-till-date order should expire")
+	assert.Equal(enums.Open, recycled.Status, "Recycled order should keep resting")
+	assert.Equal([]*entity.Order{recycled}, book.RestingOrders(asset.ID, enums.Buy))
+}
+
+func TestPooledMatchingDoesNotAllocate(t *testing.T) {
+	asset := entity.NewAsset("ASSET1", "Asset 1", 0)
+	seller := entity.NewInvestor("SELLER")
+	buyer := entity.NewInvestor("BUYER")
+
+	chanOut := make(chan *entity.Order, 1024)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.TransactionPool = entity.NewTransactionPool(16)
+	book.IDGenerator = entity.NewSequenceIDGenerator("")
+	orders := entity.NewOrderPool(16)
+
+	match := func() {
+		sellOrder := orders.Get("SELL", seller, asset, 10, 10, enums.Sell)
+		buyOrder := orders.Get("BUY", buyer, asset, 10, 10, enums.Buy)
+		book.Process(sellOrder)
+		book.Process(buyOrder)
+		for len(chanOut) > 0 {
+			<-chanOut
+		}
+		orders.Put(sellOrder)
+		orders.Put(buyOrder)
+	}
+
+	// Warming up the book, so its queues and maps are sized.
+	for i := 0; i < 100; i++ {
+		match()
+	}
+
+	assert.Equal(t, 0.0, testing.AllocsPerRun(1000, match), "Matching should not allocate in steady state")
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvestorApplyFill(t *testing.T) {
+	investor := entity.NewInvestor(uuid.NewString())
+
+	assert := assert.New(t)
+
+	investor.ApplyFill("asset", 10, 10)
+	investor.ApplyFill("asset", 10, 12)
+	position := investor.GetAssetPosition("asset")
+	assert.Equal(20, position.Shares)
+	assert.InDelta(11.0, position.AverageCost, 1e-9, "Buying should average the cost")
+
+	investor.ApplyFill("asset", -5, 15)
+	assert.Equal(15, position.Shares)
+	assert.InDelta(11.0, position.AverageCost, 1e-9, "Selling should keep the average cost")
+	assert.InDelta(20.0, position.RealizedPnL, 1e-9, "Selling should realize (15 - 11) * 5")
+
+	investor.ApplyFill("asset", -20, 10)
+	assert.Equal(-5, position.Shares)
+	assert.InDelta(5.0, position.RealizedPnL, 1e-9, "Closing the long position should realize (10 - 11) * 15")
+	assert.InDelta(10.0, position.AverageCost, 1e-9, "Flipped position should be held at the fill price")
+
+	investor.ApplyFill("asset", 5, 8)
+	assert.Equal(0, position.Shares)
+	assert.InDelta(15.0, position.RealizedPnL, 1e-9, "Covering the short position should realize (10 - 8) * 5")
+	assert.Equal(0.0, position.AverageCost, "Flat position should have no cost")
+}
+
+func TestBookPortfolio(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	other := entity.NewAsset(uuid.NewString(), "Asset 2", 100)
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 20))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+	buyInvestor.ApplyFill(other.ID, 4, 25)
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+
+	wg.Add(2)
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 10, enums.Buy))
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 12, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 12, enums.Buy))
+	wg.Wait()
+
+	summary := book.Portfolio(buyInvestor)
+
+	assert := assert.New(t)
+
+	assert.Equal(buyInvestor.ID, summary.InvestorID)
+	assert.Len(summary.Positions, 2)
+
+	otherPosition := summary.Positions[0]
+	assert.Equal(25.0, otherPosition.MarkPrice, "Assets without trades should be marked at their average cost")
+	assert.Equal(0.0, otherPosition.UnrealizedPnL)
+
+	position := summary.Positions[1]
+	assert.Equal(15, position.Shares)
+	assert.InDelta(32.0/3.0, position.AverageCost, 1e-9)
+	assert.Equal(12.0, position.MarkPrice, "Position should be marked at the last trade price")
+	assert.InDelta(180.0, position.MarketValue, 1e-9)
+	assert.InDelta(20.0, position.UnrealizedPnL, 1e-9)
+
+	assert.InDelta(280.0, summary.MarketValue, 1e-9)
+	assert.InDelta(20.0, summary.UnrealizedPnL, 1e-9)
+	assert.InDelta(20.0, summary.TotalPnL, 1e-9)
+}
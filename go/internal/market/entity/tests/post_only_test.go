@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostOnlyOrderIsRejectedWhenCrossing(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	go book.Trade()
+
+	chanIn <- entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 6, enums.Buy)
+	buyOrder.PostOnly = true
+	chanIn <- buyOrder
+
+	assert := assert.New(t)
+
+	assert.Equal(buyOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, buyOrder.Status, "Post-only order should be rejected")
+	assert.ErrorIs(buyOrder.RejectReason, entity.ErrPostOnlyWouldCross)
+	assert.Equal(0, buyOrder.TransactionsCount(), "Post-only order should not trade")
+	assert.Empty(book.Depth(a.ID).Bids, "Rejected order should not rest in the book")
+}
+
+func TestPostOnlyOrderIsRepriced(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	book.PostOnlyReprice = true
+	go book.Trade()
+
+	chanIn <- entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 6, enums.Buy)
+	buyOrder.PostOnly = true
+	chanIn <- buyOrder
+
+	assert := assert.New(t)
+
+	assert.Eventually(func() bool {
+		return len(book.Depth(a.ID).Bids) == 1
+	}, time.Second, time.Millisecond, "Re-priced order should rest in the book")
+
+	assert.Equal(enums.Open, buyOrder.Status, "Post-only order should still be open")
+	assert.InDelta(4.99, buyOrder.Price, 1e-9, "Post-only order should be re-priced one tick below the best ask")
+	assert.Equal(0, buyOrder.TransactionsCount(), "Post-only order should not trade")
+	assert.Empty(chanOut, "Nothing should be published")
+}
+
+func TestHiddenOrder(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	go book.Trade()
+
+	hiddenOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+	hiddenOrder.Hidden = true
+	chanIn <- hiddenOrder
+
+	assert := assert.New(t)
+
+	assert.Never(func() bool {
+		return len(book.Depth(a.ID).Asks) > 0
+	}, 20*time.Millisecond, time.Millisecond, "Hidden order should not show in the depth")
+
+	wg.Add(1)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 5, enums.Buy)
+	chanIn <- buyOrder
+	wg.Wait()
+
+	assert.Equal(hiddenOrder, <-chanOut, "Hidden order should be published when filled")
+	assert.Equal(buyOrder, <-chanOut, "Buy order should be published when filled")
+	assert.Equal(enums.Closed, buyOrder.Status, "Buy order should be filled by the hidden order")
+	assert.Equal(6, hiddenOrder.PendingShares, "Hidden order should have 6 pending shares")
+}
+
+func TestRepricedPostOnlyOrderStaysWithinPriceBand(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	a.ReferencePrice = 100
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(nil, chanOut, nil)
+	book.PostOnlyReprice = true
+	book.PriceBands[a.ID] = &entity.PriceBand{Static: 0.1}
+
+	// The best ask sits right at the bottom of the band.
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 90, enums.Sell))
+
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 95, enums.Buy)
+	buyOrder.PostOnly = true
+	book.Process(buyOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Rejected, buyOrder.Status, "Order re-priced outside the band should be rejected")
+	assert.Len(chanOut, 1, "Rejected order should be published")
+	assert.ErrorIs(buyOrder.RejectReason, entity.ErrPriceOutsideBand)
+	assert.Empty(book.Depth(a.ID).Bids, "Rejected order should not rest in the book")
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceBands(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	a.ReferencePrice = 10
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.PriceBands[a.ID] = &entity.PriceBand{Static: 0.1, Dynamic: 0.02}
+
+	assert := assert.New(t)
+
+	fatFingerOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 100, enums.Buy)
+	book.Process(fatFingerOrder)
+
+	assert.Equal(fatFingerOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, fatFingerOrder.Status, "Order far from the previous close should be rejected")
+	assert.ErrorIs(fatFingerOrder.RejectReason, entity.ErrPriceOutsideBand)
+
+	wg.Add(1)
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 5, 10.5, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10.5, enums.Buy))
+	wg.Wait()
+	<-chanOut
+	<-chanOut
+
+	withinStaticBandOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10.0, enums.Buy)
+	book.Process(withinStaticBandOrder)
+
+	assert.Equal(withinStaticBandOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, withinStaticBandOrder.Status, "Order far from the last trade price should be rejected")
+
+	withinBandsOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 5, 10.4, enums.Buy)
+	book.Process(withinBandsOrder)
+
+	assert.Equal(enums.Open, withinBandsOrder.Status, "Order within both bands should be accepted")
+	assert.Empty(chanOut, "Accepted order should not be published")
+}
+
+func TestVolatilityHalt(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	a.ReferencePrice = 10
+
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	clock := entity.NewFakeClock(time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC))
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+	book.PriceBands[a.ID] = &entity.PriceBand{HaltThreshold: 0.03, CoolingOff: 5 * time.Minute}
+
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10.5, enums.Sell)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 10.5, enums.Buy)
+	book.Process(sellOrder)
+	book.Process(buyOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(enums.Halted, book.Phase(a.ID), "Asset should be halted by a 5% move")
+	assert.Equal(0, buyOrder.TransactionsCount(), "No transaction should be executed when halting")
+	assert.Len(book.Depth(a.ID).Bids, 1, "Incoming order should rest during the halt")
+
+	clock.Advance(4 * time.Minute)
+	book.Tick()
+
+	assert.Equal(enums.Halted, book.Phase(a.ID), "Asset should stay halted during the cooling-off period")
+
+	wg.Add(1)
+	clock.Advance(time.Minute)
+	book.Tick()
+	wg.Wait()
+
+	assert.Equal(enums.Continuous, book.Phase(a.ID), "Asset should resume trading after the cooling-off period")
+	assert.Equal(enums.Closed, buyOrder.Status, "Buy order should be filled by the resuming auction")
+	assert.True(buyOrder.Transactions[0].Auction, "Trading should resume with an auction")
+	assert.Equal(10.5, buyOrder.Transactions[0].Price, "Auction should execute at 10.5")
+}
@@ -0,0 +1,185 @@
+package entity
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func orderIDs(orders []*entity.Order) []string {
+	ids := []string{}
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+func TestPriceLevelSideKeepsPriceTimePriority(t *testing.T) {
+	a := entity.NewAsset("ASSET1", "Asset 1", 100)
+	investor := entity.NewInvestor("INVESTOR1")
+
+	side := entity.NewPriceLevelSide(enums.Buy)
+	side.Add(entity.NewOrder("1", investor, a, 10, 10, enums.Buy))
+	side.Add(entity.NewOrder("2", investor, a, 10, 12, enums.Buy))
+	side.Add(entity.NewOrder("3", investor, a, 10, 11, enums.Buy))
+	side.Add(entity.NewOrder("4", investor, a, 10, 12, enums.Buy))
+
+	assert := assert.New(t)
+	assert.Equal(4, side.Len())
+	assert.Equal("2", side.Best().ID, "Highest bid should be the best")
+	assert.Equal([]string{"2", "4", "3", "1"}, orderIDs(side.Orders()), "Orders should be sorted by price, then arrival")
+
+	assert.Equal("2", side.PopBest().ID)
+	assert.Equal("4", side.PopBest().ID)
+	assert.Equal("3", side.Best().ID, "Emptied level should be removed")
+	assert.Equal(2, side.Len())
+}
+
+func TestPriceLevelSideFindAndRemove(t *testing.T) {
+	a := entity.NewAsset("ASSET1", "Asset 1", 100)
+	investor := entity.NewInvestor("INVESTOR1")
+
+	side := entity.NewPriceLevelSide(enums.Sell)
+	first := entity.NewOrder("1", investor, a, 10, 10, enums.Sell)
+	second := entity.NewOrder("2", investor, a, 10, 10, enums.Sell)
+	third := entity.NewOrder("3", investor, a, 10, 11, enums.Sell)
+	side.Add(first)
+	side.Add(second)
+	side.Add(third)
+
+	assert := assert.New(t)
+	assert.Same(second, side.Find(investor.ID, "2"))
+	assert.Nil(side.Find(investor.ID, "4"))
+
+	assert.True(side.Remove(first), "Resting order should be removed")
+	assert.False(side.Remove(first), "Removed order should not be removed twice")
+	assert.Nil(side.Find(investor.ID, "1"), "Removed order should leave the index")
+	assert.Same(second, side.Best())
+
+	other := entity.NewPriceLevelSide(enums.Sell)
+	assert.False(other.Remove(second), "Order resting on another side should not be removed")
+
+	removed := side.RemoveIf(func(order *entity.Order) bool { return order.Price == 10 })
+	assert.Equal([]string{"2"}, orderIDs(removed))
+	assert.Same(third, side.Best())
+	assert.Equal(1, side.Len())
+
+	side.PopBest()
+	assert.Nil(side.Best(), "Empty side should have no best order")
+	assert.Nil(side.PopBest())
+	assert.Equal(0, side.Len())
+}
+
+func TestBookSidesFindOrdersByInvestorAndID(t *testing.T) {
+	a := entity.NewAsset("ASSET1", "Asset 1", 100)
+	firstInvestor := entity.NewInvestor("INVESTOR1")
+	secondInvestor := entity.NewInvestor("INVESTOR2")
+
+	assert := assert.New(t)
+
+	for _, side := range []entity.BookSide{entity.NewOrderQueue(), entity.NewPriceLevelSide(enums.Sell)} {
+		first := entity.NewOrder("1", firstInvestor, a, 10, 10, enums.Sell)
+		second := entity.NewOrder("1", secondInvestor, a, 10, 11, enums.Sell)
+		side.Add(first)
+		side.Add(second)
+
+		assert.Same(first, side.Find(firstInvestor.ID, "1"))
+		assert.Same(second, side.Find(secondInvestor.ID, "1"), "Orders of different investors should not collide")
+
+		side.Remove(second)
+		assert.Same(first, side.Find(firstInvestor.ID, "1"), "Removing an order should keep the other investor's order")
+		assert.Nil(side.Find(secondInvestor.ID, "1"))
+	}
+}
+
+func TestPriceLevelBookMatchesLikeOrderQueueBook(t *testing.T) {
+	for _, priceLevels := range []bool{false, true} {
+		a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+		sellInvestor := entity.NewInvestor(uuid.NewString())
+		sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 30))
+		buyInvestor := entity.NewInvestor(uuid.NewString())
+
+		chanOut := make(chan *entity.Order, 20)
+		book := entity.NewBook(nil, chanOut, nil)
+		book.PriceLevels = priceLevels
+
+		firstSell := entity.NewOrder("SELL1", sellInvestor, a, 10, 6, enums.Sell)
+		secondSell := entity.NewOrder("SELL2", sellInvestor, a, 10, 5, enums.Sell)
+		thirdSell := entity.NewOrder("SELL3", sellInvestor, a, 10, 5, enums.Sell)
+		book.Process(firstSell)
+		book.Process(secondSell)
+		book.Process(thirdSell)
+
+		buyOrder := entity.NewOrder("BUY1", buyInvestor, a, 15, 6, enums.Buy)
+		book.Process(buyOrder)
+
+		assert := assert.New(t)
+		assert.Equal(enums.Closed, secondSell.Status, "Best priced order should fill first")
+		assert.Equal(5, thirdSell.PendingShares, "Next order at the price should fill next")
+		assert.Equal(10, firstSell.PendingShares, "Worse priced order should not fill")
+		assert.Equal([]string{"SELL3", "SELL1"}, orderIDs(book.RestingOrders(a.ID, enums.Sell)))
+
+		cancelled, ok := book.CancelOrderByID(a.ID, sellInvestor.ID, "SELL1")
+		assert.True(ok, "Resting order should be cancelled by ID")
+		assert.Same(firstSell, cancelled)
+		assert.Equal(enums.Cancelled, firstSell.Status)
+
+		_, ok = book.CancelOrderByID(a.ID, sellInvestor.ID, "SELL1")
+		assert.False(ok, "Cancelled order should no longer be found")
+		assert.Equal([]string{"SELL3"}, orderIDs(book.RestingOrders(a.ID, enums.Sell)))
+	}
+}
+
+func TestBookSidesAgree(t *testing.T) {
+	a := entity.NewAsset("ASSET1", "Asset 1", 100)
+	investor := entity.NewInvestor("INVESTOR1")
+
+	assert := assert.New(t)
+
+	for _, side := range []enums.Side{enums.Buy, enums.Sell} {
+		queue := entity.NewOrderQueue()
+		levels := entity.NewPriceLevelSide(side)
+		assert.Nil(queue.PopBest(), "Empty queue should have nothing to pop")
+		assert.Nil(levels.PopBest(), "Empty side should have nothing to pop")
+
+		random := rand.New(rand.NewSource(1))
+		resting := []*entity.Order{}
+		for i := 0; i < 2000; i++ {
+			if len(resting) > 0 && random.Intn(3) == 0 {
+				j := random.Intn(len(resting))
+				order := resting[j]
+				resting = append(resting[:j], resting[j+1:]...)
+				assert.True(queue.Remove(order))
+				assert.True(levels.Remove(order))
+				continue
+			}
+
+			order := entity.NewOrder(fmt.Sprintf("ORDER%d", i), investor, a, 1, float64(random.Intn(50)), side)
+			resting = append(resting, order)
+			queue.Add(order)
+			levels.Add(order)
+		}
+
+		// Orders outside a book have no arrival number, so only their prices
+		// are ordered.
+		queuePrices := []float64{}
+		for _, order := range queue.Orders() {
+			queuePrices = append(queuePrices, order.Price)
+		}
+		levelPrices := []float64{}
+		for _, order := range levels.Orders() {
+			levelPrices = append(levelPrices, order.Price)
+		}
+		assert.Equal(queuePrices, levelPrices, "Both sides should keep the same price priority")
+
+		for queue.Len() > 0 {
+			assert.Equal(queue.PopBest().Price, levels.PopBest().Price)
+		}
+		assert.Equal(0, levels.Len())
+	}
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiskGateOrderLimits(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	investor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 5))
+
+	clock := entity.NewFakeClock(time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC))
+	gate := entity.NewRiskGate(clock)
+	gate.SetLimits(investor.ID, entity.RiskLimits{
+		MaxOrderNotional: 100,
+		MaxOrderShares:   20,
+		MaxOpenOrders:    2,
+		MaxPosition:      15,
+	})
+
+	assert := assert.New(t)
+
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 30, 1, enums.Buy)), entity.ErrMaxOrderShares)
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 11, 10, enums.Buy)), entity.ErrMaxOrderNotional)
+
+	firstOrder := entity.NewOrder(uuid.NewString(), investor, a, 6, 1, enums.Buy)
+	assert.NoError(gate.Check(firstOrder))
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 5, 1, enums.Buy)), entity.ErrMaxPosition,
+		"5 owned plus 6 and 5 pending should breach the 15 shares position limit")
+
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 4, 1, enums.Buy)))
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Sell)), entity.ErrMaxOpenOrders)
+
+	gate.Release(firstOrder)
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Sell)), "Released orders should not count as open")
+}
+
+func TestRiskGateOrderRate(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	clock := entity.NewFakeClock(time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC))
+	gate := entity.NewRiskGate(clock)
+	gate.SetDefaultLimits(entity.RiskLimits{MaxOrdersPerSecond: 2})
+
+	assert := assert.New(t)
+
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)))
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)))
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), entity.ErrOrderRateExceeded)
+
+	clock.Advance(time.Second)
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), "Rate should be measured over a second")
+}
+
+func TestRiskGateRejectedOrdersDoNotCountTowardsRate(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	clock := entity.NewFakeClock(time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC))
+	gate := entity.NewRiskGate(clock)
+	gate.SetDefaultLimits(entity.RiskLimits{MaxOrdersPerSecond: 2, MaxOrderShares: 10})
+
+	assert := assert.New(t)
+
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)))
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 11, 1, enums.Buy)), entity.ErrMaxOrderShares)
+	}
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), "Rejected orders should not use up the rate")
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), entity.ErrOrderRateExceeded)
+
+	clock.Advance(time.Second)
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)))
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)))
+	for i := 0; i < 5; i++ {
+		clock.Advance(100 * time.Millisecond)
+		assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), entity.ErrOrderRateExceeded, "Orders over the rate should be rejected")
+	}
+	clock.Advance(500 * time.Millisecond)
+	assert.NoError(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), "Orders rejected over the rate should not extend it")
+}
+
+func TestRiskGateRejectsDuplicateOrderIDs(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+
+	gate := entity.NewRiskGate(entity.NewFakeClock(time.Date(2023, 9, 4, 12, 0, 0, 0, time.UTC)))
+	gate.SetDefaultLimits(entity.RiskLimits{MaxOpenOrders: 1})
+
+	order := entity.NewOrder("ORDER1", investor, a, 1, 1, enums.Buy)
+	duplicate := entity.NewOrder("ORDER1", investor, a, 1, 1, enums.Buy)
+
+	assert := assert.New(t)
+
+	assert.NoError(gate.Check(order))
+	assert.ErrorIs(gate.Check(duplicate), entity.ErrDuplicateOrderID)
+
+	gate.Release(duplicate)
+	assert.ErrorIs(gate.Check(entity.NewOrder(uuid.NewString(), investor, a, 1, 1, enums.Buy)), entity.ErrMaxOpenOrders, "Releasing the duplicate should keep the open order counted")
+
+	gate.Release(order)
+	assert.NoError(gate.Check(duplicate), "ID should be reusable once the order is released")
+}
+
+func TestRiskGateLoadLimits(t *testing.T) {
+	gate := entity.NewRiskGate(entity.SystemClock{})
+	gate.SetLimits("investor", entity.RiskLimits{MaxOrderShares: 1})
+
+	assert := assert.New(t)
+
+	err := gate.LoadLimits(strings.NewReader(`{
+		"default": {"max_open_orders": 10},
+		"investors": {"other": {"max_order_shares": 50}}
+	}`))
+
+	assert.NoError(err)
+	assert.Equal(entity.RiskLimits{MaxOpenOrders: 10}, gate.Limits("investor"), "Reloading should replace every limit")
+	assert.Equal(entity.RiskLimits{MaxOrderShares: 50}, gate.Limits("other"))
+
+	assert.Error(gate.LoadLimits(strings.NewReader(`{"default":`)))
+	assert.Equal(entity.RiskLimits{MaxOrderShares: 50}, gate.Limits("other"), "Invalid documents should not change the limits")
+}
+
+func TestBookRejectsOrdersBreachingRiskLimits(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.RiskGate = entity.NewRiskGate(book.Clock)
+	book.RiskGate.SetLimits(buyInvestor.ID, entity.RiskLimits{MaxDailyTradedValue: 60})
+
+	wg.Add(1)
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy))
+	wg.Wait()
+	<-chanOut
+	<-chanOut
+
+	rejectedOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 3, 5, enums.Buy)
+	book.Process(rejectedOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(rejectedOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, rejectedOrder.Status, "Order should be rejected")
+	assert.ErrorIs(rejectedOrder.RejectReason, entity.ErrMaxDailyTradedValue)
+}
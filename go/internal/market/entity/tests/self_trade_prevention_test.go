@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSelfTradeBook(mode enums.SelfTradePrevention) (chan *entity.Order, chan *entity.Order, *sync.WaitGroup) {
+	chanIn := make(chan *entity.Order)
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	book := entity.NewBook(chanIn, chanOut, &wg)
+	book.SelfTradePrevention = mode
+	go book.Trade()
+
+	return chanIn, chanOut, &wg
+}
+
+func TestSelfTradeCancelNewest(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	investor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+
+	chanIn, chanOut, _ := newSelfTradeBook(enums.CancelNewest)
+
+	sellOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Sell)
+	chanIn <- sellOrder
+	buyOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Buy)
+	chanIn <- buyOrder
+
+	assert := assert.New(t)
+
+	assert.Equal(buyOrder, <-chanOut, "Only the incoming order should be published")
+	assert.Equal(enums.Cancelled, buyOrder.Status, "Incoming order should be cancelled")
+	assert.Equal(enums.Open, sellOrder.Status, "Resting order should still be open")
+	assert.Equal(0, buyOrder.TransactionsCount(), "No transaction should be created")
+	assert.Equal(10, investor.GetAssetPosition(a.ID).Shares, "Investor should still have 10 shares")
+}
+
+func TestSelfTradeCancelOldest(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	investor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	otherInvestor := entity.NewInvestor(uuid.NewString())
+	otherInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+
+	chanIn, chanOut, wg := newSelfTradeBook(enums.CancelOldest)
+
+	ownSellOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Sell)
+	chanIn <- ownSellOrder
+	otherSellOrder := entity.NewOrder(uuid.NewString(), otherInvestor, a, 10, 5, enums.Sell)
+	chanIn <- otherSellOrder
+
+	wg.Add(1)
+	buyOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Buy)
+	chanIn <- buyOrder
+	wg.Wait()
+
+	assert := assert.New(t)
+
+	assert.Equal(ownSellOrder, <-chanOut, "The resting order of the same investor should be published first")
+	assert.Equal(otherSellOrder, <-chanOut, "The filled resting order should be published")
+	assert.Equal(buyOrder, <-chanOut, "The filled incoming order should be published")
+	assert.Equal(enums.Cancelled, ownSellOrder.Status, "Resting order of the same investor should be cancelled")
+	assert.Equal(enums.Closed, otherSellOrder.Status, "Resting order of the other investor should be filled")
+	assert.Equal(enums.Closed, buyOrder.Status, "Incoming order should be filled")
+	assert.Equal(1, buyOrder.TransactionsCount(), "Incoming order should have 1 transaction")
+	assert.Equal(otherSellOrder, buyOrder.Transactions[0].SellingOrder, "Incoming order should trade with the other investor")
+}
+
+func TestSelfTradeCancelBoth(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	investor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+
+	chanIn, chanOut, _ := newSelfTradeBook(enums.CancelBoth)
+
+	sellOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Sell)
+	chanIn <- sellOrder
+	buyOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 6, enums.Buy)
+	chanIn <- buyOrder
+
+	assert := assert.New(t)
+
+	assert.Equal(sellOrder, <-chanOut, "Resting order should be published")
+	assert.Equal(buyOrder, <-chanOut, "Incoming order should be published")
+	assert.Equal(enums.Cancelled, sellOrder.Status, "Resting order should be cancelled")
+	assert.Equal(enums.Cancelled, buyOrder.Status, "Incoming order should be cancelled")
+}
+
+func TestSelfTradeDecrementAndCancel(t *testing.T) {
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	investor := entity.NewInvestor(uuid.NewString())
+	investor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+
+	chanIn, chanOut, _ := newSelfTradeBook(enums.DecrementAndCancel)
+
+	sellOrder := entity.NewOrder(uuid.NewString(), investor, a, 10, 5, enums.Sell)
+	chanIn <- sellOrder
+	buyOrder := entity.NewOrder(uuid.NewString(), investor, a, 4, 5, enums.Buy)
+	chanIn <- buyOrder
+
+	assert := assert.New(t)
+
+	assert.Equal(sellOrder, <-chanOut, "Resting order should be published")
+	assert.Equal(buyOrder, <-chanOut, "Incoming order should be published")
+	assert.Equal(6, sellOrder.PendingShares, "Resting order should be decremented by 4 shares")
+	assert.Equal(enums.Open, sellOrder.Status, "Resting order should still be open")
+	assert.Equal(0, buyOrder.PendingShares, "Incoming order should have no pending shares")
+	assert.Equal(enums.Cancelled, buyOrder.Status, "Incoming order should be cancelled")
+	assert.Equal(10, investor.GetAssetPosition(a.ID).Shares, "Investor should still have 10 shares")
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func newScheduleTestLocation(t *testing.T) *time.Location {
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	return location
+}
+
+func TestTradingScheduleSessions(t *testing.T) {
+	location := newScheduleTestLocation(t)
+	schedule := entity.NewTradingSchedule(location, 10*time.Hour, 17*time.Hour)
+
+	// 2023-09-04 is a Monday.
+	monday := time.Date(2023, 9, 4, 0, 0, 0, 0, location)
+	schedule.AddHoliday(monday.AddDate(0, 0, 3))
+	schedule.AddHalfDay(monday.AddDate(0, 0, 4), 13*time.Hour)
+
+	assert := assert.New(t)
+
+	assert.False(schedule.IsOpen(monday.Add(9*time.Hour+59*time.Minute)), "Market should be closed before the open")
+	assert.True(schedule.IsOpen(monday.Add(10*time.Hour)), "Market should be open at the open")
+	assert.True(schedule.IsOpen(monday.Add(16*time.Hour+59*time.Minute)), "Market should be open before the close")
+	assert.False(schedule.IsOpen(monday.Add(17*time.Hour)), "Market should be closed at the close")
+	assert.True(schedule.IsOpen(monday.Add(10*time.Hour).UTC()), "Time zone of the checked time should not matter")
+
+	assert.False(schedule.IsOpen(monday.AddDate(0, 0, 3).Add(12*time.Hour)), "Market should be closed on holidays")
+	assert.True(schedule.IsOpen(monday.AddDate(0, 0, 4).Add(12*time.Hour)), "Market should be open on half days")
+	assert.False(schedule.IsOpen(monday.AddDate(0, 0, 4).Add(14*time.Hour)), "Market should close early on half days")
+	assert.False(schedule.IsOpen(monday.AddDate(0, 0, 5).Add(12*time.Hour)), "Market should be closed on weekends")
+}
+
+func TestOrdersOutsideTradingHours(t *testing.T) {
+	location := newScheduleTestLocation(t)
+	monday := time.Date(2023, 9, 4, 0, 0, 0, 0, location)
+
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	sellInvestor.AddAssetPosition(entity.NewInvestorAssetPosition(a.ID, 10))
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	clock := entity.NewFakeClock(monday.Add(8 * time.Hour))
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+	book.Schedules[a.ID] = entity.NewTradingSchedule(location, 10*time.Hour, 17*time.Hour)
+
+	rejectedOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+	book.Process(rejectedOrder)
+
+	assert := assert.New(t)
+
+	assert.Equal(rejectedOrder, <-chanOut, "Rejected order should be published")
+	assert.Equal(enums.Rejected, rejectedOrder.Status, "Order should be rejected before the open")
+	assert.ErrorIs(rejectedOrder.RejectReason, entity.ErrOutsideTradingHours)
+
+	book.QueueOutsideHours = true
+
+	sellOrder := entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 5, enums.Sell)
+	buyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+	book.Process(sellOrder)
+	book.Process(buyOrder)
+
+	assert.Equal(enums.Open, buyOrder.Status, "Order should be queued before the open")
+	assert.Empty(book.Depth(a.ID).Asks, "Queued orders should not be in the book")
+
+	wg.Add(1)
+	clock.Set(monday.Add(10 * time.Hour))
+	book.Tick()
+	wg.Wait()
+
+	assert.Equal(sellOrder, <-chanOut, "Queued sell order should be matched at the open")
+	assert.Equal(buyOrder, <-chanOut, "Queued buy order should be matched at the open")
+	assert.Equal(enums.Closed, buyOrder.Status, "Queued buy order should be filled at the open")
+	assert.Equal(enums.Closed, sellOrder.Status, "Queued sell order should be filled at the open")
+}
+
+func TestDayOrdersExpireAtClose(t *testing.T) {
+	location := newScheduleTestLocation(t)
+	monday := time.Date(2023, 9, 4, 0, 0, 0, 0, location)
+
+	a := entity.NewAsset(uuid.NewString(), "Asset 1", 100)
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	chanOut := make(chan *entity.Order, 10)
+	wg := sync.WaitGroup{}
+
+	clock := entity.NewFakeClock(monday.Add(11 * time.Hour))
+	book := entity.NewBook(nil, chanOut, &wg)
+	book.Clock = clock
+	book.Schedules[a.ID] = entity.NewTradingSchedule(location, 10*time.Hour, 17*time.Hour)
+
+	dayOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 5, enums.Buy)
+	dayOrder.TimeInForce = enums.Day
+	goodTillCancelOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 10, 4, enums.Buy)
+	book.Process(dayOrder)
+	book.Process(goodTillCancelOrder)
+
+	clock.Set(monday.Add(16 * time.Hour))
+	book.Tick()
+
+	assert := assert.New(t)
+
+	assert.Empty(chanOut, "Nothing should expire before the close")
+
+	clock.Set(monday.Add(17 * time.Hour))
+	book.Tick()
+
+	assert.Equal(dayOrder, <-chanOut, "Expired order should be published")
+	assert.Equal(enums.Expired, dayOrder.Status, "Day order should expire at the close")
+	assert.Equal(enums.Open, goodTillCancelOrder.Status, "Good till cancel order should stay open")
+	assert.Equal([]entity.PriceLevel{{Price: 4, Shares: 10, Orders: 1}}, book.Depth(a.ID).Bids)
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// AssetStatus is published on the book's status stream whenever the trading
+// phase of an asset changes. An empty AssetID stands for the whole market.
+type AssetStatus struct {
+	AssetID  string
+	Phase    enums.TradingPhase
+	Reason   string
+	DateTime time.Time
+}
+
+// Phase returns the trading phase of an asset.
+func (b *Book) Phase(assetID string) enums.TradingPhase {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.phase(assetID)
+}
+
+// SetPhase moves a single asset to a trading phase.
+func (b *Book) SetPhase(assetID string, phase enums.TradingPhase) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.setPhase(assetID, phase, "")
+}
+
+// SetMarketPhase moves every asset to a trading phase, dropping the phases
+// set for single assets and whatever a market halt would have restored.
+func (b *Book) SetMarketPhase(phase enums.TradingPhase) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.marketHalt = nil
+	b.setMarketPhase(phase, "")
+}
+
+func (b *Book) phase(assetID string) enums.TradingPhase {
+	if phase, ok := b.phases[assetID]; ok {
+		return phase
+	}
+	return b.marketPhase
+}
+
+func (b *Book) setPhase(assetID string, phase enums.TradingPhase, reason string) {
+	b.phases[assetID] = phase
+	b.publishStatus(assetID, phase, reason)
+}
+
+func (b *Book) setMarketPhase(phase enums.TradingPhase, reason string) {
+	b.marketPhase = phase
+	b.phases = make(map[string]enums.TradingPhase)
+	b.publishStatus("", phase, reason)
+}
+
+// publishStatus queues a status update for StatusChanOut, when the book has
+// one, sent once the book's lock is released.
+func (b *Book) publishStatus(assetID string, phase enums.TradingPhase, reason string) {
+	if b.StatusChanOut == nil {
+		return
+	}
+	b.outbox.statuses = append(b.outbox.statuses, &AssetStatus{
+		AssetID:  assetID,
+		Phase:    phase,
+		Reason:   reason,
+		DateTime: b.Clock.Now(),
+	})
+}
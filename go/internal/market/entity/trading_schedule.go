@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+const dateLayout = "2006-01-02"
+
+// TradingSchedule holds the trading hours of an asset.
+//
+// Open and Close are offsets from midnight in the schedule's time zone.
+// Trading happens on weekdays, except on holidays; half days close earlier
+// than usual.
+type TradingSchedule struct {
+	Location *time.Location
+	Open     time.Duration
+	Close    time.Duration
+	Holidays map[string]bool
+	HalfDays map[string]time.Duration
+}
+
+func NewTradingSchedule(location *time.Location, open time.Duration, close time.Duration) *TradingSchedule {
+	return &TradingSchedule{
+		Location: location,
+		Open:     open,
+		Close:    close,
+		Holidays: map[string]bool{},
+		HalfDays: map[string]time.Duration{},
+	}
+}
+
+// AddHoliday marks the day of date as a day without trading.
+func (s *TradingSchedule) AddHoliday(date time.Time) {
+	s.Holidays[date.In(s.Location).Format(dateLayout)] = true
+}
+
+// AddHalfDay makes the day of date close at the given offset from midnight.
+func (s *TradingSchedule) AddHalfDay(date time.Time, close time.Duration) {
+	s.HalfDays[date.In(s.Location).Format(dateLayout)] = close
+}
+
+// Session returns the opening and closing times of the trading session held
+// on the day of t. It reports false when there is no trading on that day.
+func (s *TradingSchedule) Session(t time.Time) (time.Time, time.Time, bool) {
+	local := t.In(s.Location)
+	day := local.Format(dateLayout)
+
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday || s.Holidays[day] {
+		return time.Time{}, time.Time{}, false
+	}
+
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.Location)
+	close := s.Close
+	if halfDayClose, ok := s.HalfDays[day]; ok {
+		close = halfDayClose
+	}
+
+	return midnight.Add(s.Open), midnight.Add(close), true
+}
+
+// IsOpen reports whether trading is allowed at t.
+func (s *TradingSchedule) IsOpen(t time.Time) bool {
+	open, close, ok := s.Session(t)
+	return ok && !t.Before(open) && t.Before(close)
+}
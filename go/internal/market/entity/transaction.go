@@ -1,34 +1,83 @@
 package entity
 
 import (
+	"strconv"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/medina325/stock_market/go/internal/market/enums"
 )
 
 type Transaction struct {
-	ID           string
+	// Sequence is the number a SequenceIDGenerator handed out for the
+	// transaction, or 0 when it was given its ID as a string.
+	Sequence     uint64
+	id           string
+	idPrefix     string
 	SellingOrder *Order
 	BuyingOrder  *Order
 	Shares       int
 	Price        float64
 	Total        float64
 	DateTime     time.Time
+
+	// Aggressor is the side of the order that took liquidity.
+	Aggressor enums.Side
+	BuyerFee  float64
+	SellerFee float64
+	// BuyerNet is what the buyer pays, fees included.
+	BuyerNet float64
+	// SellerNet is what the seller receives, fees deducted.
+	SellerNet float64
+	// Auction tells the transaction was executed by an auction, in which
+	// case neither order took liquidity.
+	Auction bool
+	// TraceID is the trace ID of the aggressor order.
+	TraceID string
+}
+
+func NewTransaction(transactionID string, sellingOrder *Order, buyingOrder *Order, shares int, price float64, aggressor enums.Side, dateTime time.Time) *Transaction {
+	t := &Transaction{}
+	t.init(transactionID, sellingOrder, buyingOrder, shares, price, aggressor, dateTime)
+	return t
 }
 
-func NewTransaction(sellingOrder *Order, buyingOrder *Order, shares int, price float64) *Transaction {
+// init sets a transaction up as NewTransaction does, overwriting whatever a
+// pooled transaction held before.
+func (t *Transaction) init(transactionID string, sellingOrder *Order, buyingOrder *Order, shares int, price float64, aggressor enums.Side, dateTime time.Time) {
 	total := price * float64(shares)
 
-	return &Transaction{
-		ID:           uuid.New().String(),
+	*t = Transaction{
+		id:           transactionID,
 		SellingOrder: sellingOrder,
 		BuyingOrder:  buyingOrder,
 		Shares:       shares,
 		Price:        price,
 		Total:        total,
-		DateTime:     time.Now(),
+		DateTime:     dateTime,
+		Aggressor:    aggressor,
+		BuyerNet:     total,
+		SellerNet:    total,
+	}
+}
+
+// ID returns the ID of the transaction. An ID handed out by a
+// SequenceIDGenerator is formatted from its Sequence on every call, so the
+// book never allocates it while matching.
+func (t *Transaction) ID() string {
+	if t.Sequence == 0 {
+		return t.id
 	}
+	return t.idPrefix + strconv.FormatUint(t.Sequence, 10)
+}
+
+// ApplyFees charges the buyer and the seller the fees of the schedule,
+// updating the net amounts of the transaction. Auction transactions are
+// charged maker fees on both sides.
+func (t *Transaction) ApplyFees(schedule *FeeSchedule) {
+	t.BuyerFee = schedule.Fee(t.BuyingOrder, t.Total, !t.Auction && t.Aggressor == enums.Buy)
+	t.SellerFee = schedule.Fee(t.SellingOrder, t.Total, !t.Auction && t.Aggressor == enums.Sell)
+	t.BuyerNet = t.Total + t.BuyerFee
+	t.SellerNet = t.Total - t.SellerFee
 }
 
 func (t *Transaction) LiquidateBuyPendingShares() {
@@ -40,11 +89,11 @@ func (t *Transaction) LiquidateSellPendingShares() {
 }
 
 func (t *Transaction) UpdateSellOrderAssetPosition() {
-	t.SellingOrder.Investor.UpdateAssetPosition(t.SellingOrder.Asset.ID, -t.Shares)
+	t.SellingOrder.Investor.ApplyFill(t.SellingOrder.Asset.ID, -t.Shares, t.Price)
 }
 
 func (t *Transaction) UpdateBuyOrderAssetPosition() {
-	t.BuyingOrder.Investor.UpdateAssetPosition(t.BuyingOrder.Asset.ID, t.Shares)
+	t.BuyingOrder.Investor.ApplyFill(t.BuyingOrder.Asset.ID, t.Shares, t.Price)
 }
 
 func (t *Transaction) UpdateBuyOrderStatus() {
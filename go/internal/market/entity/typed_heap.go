@@ -0,0 +1,86 @@
+package entity
+
+// The functions below keep a slice ordered as a binary heap by less, the same
+// way container/heap does, but typed: elements go in and out without being
+// boxed in interfaces or asserted back, and the comparisons are plain
+// function calls.
+
+// heapInit orders a whole slice as a heap.
+func heapInit[S ~[]E, E any](h S, less func(a, b E) bool) {
+	n := len(h)
+	for i := n/2 - 1; i >= 0; i-- {
+		heapDown(h, i, n, less)
+	}
+}
+
+// heapPush adds an element to a heap.
+func heapPush[S ~[]E, E any](h *S, x E, less func(a, b E) bool) {
+	*h = append(*h, x)
+	heapUp(*h, len(*h)-1, less)
+}
+
+// heapPop removes and returns the top of a heap.
+func heapPop[S ~[]E, E any](h *S, less func(a, b E) bool) E {
+	old := *h
+	n := len(old) - 1
+	old[0], old[n] = old[n], old[0]
+	heapDown(old, 0, n, less)
+
+	top := old[n]
+	// Clearing the reference so the popped element can be garbage collected
+	var zero E
+	old[n] = zero
+	*h = old[:n]
+	return top
+}
+
+// heapRemove removes and returns the element at index i of a heap.
+func heapRemove[S ~[]E, E any](h *S, i int, less func(a, b E) bool) E {
+	old := *h
+	n := len(old) - 1
+	if n != i {
+		old[i], old[n] = old[n], old[i]
+		if !heapDown(old, i, n, less) {
+			heapUp(old, i, less)
+		}
+	}
+
+	removed := old[n]
+	var zero E
+	old[n] = zero
+	*h = old[:n]
+	return removed
+}
+
+func heapUp[S ~[]E, E any](h S, j int, less func(a, b E) bool) {
+	for {
+		i := (j - 1) / 2
+		if i == j || !less(h[j], h[i]) {
+			break
+		}
+		h[i], h[j] = h[j], h[i]
+		j = i
+	}
+}
+
+// heapDown sifts the element at i0 down the first n elements of h. It
+// reports whether the element moved.
+func heapDown[S ~[]E, E any](h S, i0, n int, less func(a, b E) bool) bool {
+	i := i0
+	for {
+		j1 := 2*i + 1
+		if j1 >= n || j1 < 0 {
+			break
+		}
+		j := j1
+		if j2 := j1 + 1; j2 < n && less(h[j2], h[j1]) {
+			j = j2
+		}
+		if !less(h[j], h[i]) {
+			break
+		}
+		h[i], h[j] = h[j], h[i]
+		i = j
+	}
+	return i > i0
+}
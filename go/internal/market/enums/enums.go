@@ -0,0 +1,10 @@
+// Package enums holds the typed constants shared by the market entities.
+package enums
+
+import "strings"
+
+// equalFold reports whether text names the constant called name, ignoring
+// case and surrounding whitespace.
+func equalFold(name, text string) bool {
+	return strings.EqualFold(name, strings.TrimSpace(text))
+}
@@ -0,0 +1,60 @@
+package enums
+
+import "fmt"
+
+// OrderEventType represents what happened to an order in the audit trail.
+type OrderEventType int
+
+const (
+	OrderReceived OrderEventType = iota
+	OrderAccepted
+	OrderRejected
+	OrderAmended
+	OrderCancelled
+	OrderFilled
+	OrderExpired
+)
+
+var orderEventTypeNames = map[OrderEventType]string{
+	OrderReceived:  "RECEIVED",
+	OrderAccepted:  "ACCEPTED",
+	OrderRejected:  "REJECTED",
+	OrderAmended:   "AMENDED",
+	OrderCancelled: "CANCELLED",
+	OrderFilled:    "FILLED",
+	OrderExpired:   "EXPIRED",
+}
+
+func (e OrderEventType) String() string {
+	if name, ok := orderEventTypeNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("OrderEventType(%d)", int(e))
+}
+
+// ParseOrderEventType converts a textual event type (e.g. "FILLED") into an
+// OrderEventType.
+func ParseOrderEventType(text string) (OrderEventType, error) {
+	for eventType, name := range orderEventTypeNames {
+		if equalFold(name, text) {
+			return eventType, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid order event type %q", text)
+}
+
+func (e OrderEventType) MarshalText() ([]byte, error) {
+	if _, ok := orderEventTypeNames[e]; !ok {
+		return nil, fmt.Errorf("enums: invalid order event type %d", int(e))
+	}
+	return []byte(e.String()), nil
+}
+
+func (e *OrderEventType) UnmarshalText(text []byte) error {
+	eventType, err := ParseOrderEventType(string(text))
+	if err != nil {
+		return err
+	}
+	*e = eventType
+	return nil
+}
@@ -1,6 +1,56 @@
 package enums
 
+import "fmt"
+
+// OrderStatus represents the lifecycle state of an order.
+type OrderStatus int
+
 const (
-	Open   = 0
-	Closed = 1
+	Open OrderStatus = iota
+	Closed
+	Cancelled
+	Rejected
+	Expired
 )
+
+var orderStatusNames = map[OrderStatus]string{
+	Open:      "OPEN",
+	Closed:    "CLOSED",
+	Cancelled: "CANCELLED",
+	Rejected:  "REJECTED",
+	Expired:   "EXPIRED",
+}
+
+func (s OrderStatus) String() string {
+	if name, ok := orderStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("OrderStatus(%d)", int(s))
+}
+
+// ParseOrderStatus converts a textual status (e.g. "OPEN" or "closed") into
+// an OrderStatus.
+func ParseOrderStatus(text string) (OrderStatus, error) {
+	for status, name := range orderStatusNames {
+		if equalFold(name, text) {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid order status %q", text)
+}
+
+func (s OrderStatus) MarshalText() ([]byte, error) {
+	if _, ok := orderStatusNames[s]; !ok {
+		return nil, fmt.Errorf("enums: invalid order status %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *OrderStatus) UnmarshalText(text []byte) error {
+	status, err := ParseOrderStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
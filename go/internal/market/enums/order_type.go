@@ -1,6 +1,57 @@
 package enums
 
+import "fmt"
+
+// Side represents the side of the book an order belongs to.
+type Side int
+
 const (
-	Buy  = 0
-	Sell = 1
+	Buy Side = iota
+	Sell
 )
+
+var sideNames = map[Side]string{
+	Buy:  "BUY",
+	Sell: "SELL",
+}
+
+func (s Side) String() string {
+	if name, ok := sideNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Side(%d)", int(s))
+}
+
+// Opposite returns the side an order of side s is matched against.
+func (s Side) Opposite() Side {
+	if s == Buy {
+		return Sell
+	}
+	return Buy
+}
+
+// ParseSide converts a textual side (e.g. "BUY" or "sell") into a Side.
+func ParseSide(text string) (Side, error) {
+	for side, name := range sideNames {
+		if equalFold(name, text) {
+			return side, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid side %q", text)
+}
+
+func (s Side) MarshalText() ([]byte, error) {
+	if _, ok := sideNames[s]; !ok {
+		return nil, fmt.Errorf("enums: invalid side %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *Side) UnmarshalText(text []byte) error {
+	side, err := ParseSide(string(text))
+	if err != nil {
+		return err
+	}
+	*s = side
+	return nil
+}
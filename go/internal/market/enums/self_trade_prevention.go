@@ -0,0 +1,61 @@
+package enums
+
+import "fmt"
+
+// SelfTradePrevention decides what the book does when an incoming order would
+// match a resting order placed by the same investor.
+type SelfTradePrevention int
+
+const (
+	// CancelNewest cancels the incoming order and keeps the resting one.
+	CancelNewest SelfTradePrevention = iota
+	// CancelOldest cancels the resting order and lets the incoming order keep
+	// matching against the rest of the book.
+	CancelOldest
+	// CancelBoth cancels both the incoming and the resting order.
+	CancelBoth
+	// DecrementAndCancel reduces both orders by the smaller pending quantity,
+	// cancelling whichever of them ends up with no pending shares.
+	DecrementAndCancel
+)
+
+var selfTradePreventionNames = map[SelfTradePrevention]string{
+	CancelNewest:       "CANCEL_NEWEST",
+	CancelOldest:       "CANCEL_OLDEST",
+	CancelBoth:         "CANCEL_BOTH",
+	DecrementAndCancel: "DECREMENT_AND_CANCEL",
+}
+
+func (s SelfTradePrevention) String() string {
+	if name, ok := selfTradePreventionNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("SelfTradePrevention(%d)", int(s))
+}
+
+// ParseSelfTradePrevention converts a textual mode (e.g. "CANCEL_OLDEST")
+// into a SelfTradePrevention.
+func ParseSelfTradePrevention(text string) (SelfTradePrevention, error) {
+	for mode, name := range selfTradePreventionNames {
+		if equalFold(name, text) {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid self-trade prevention mode %q", text)
+}
+
+func (s SelfTradePrevention) MarshalText() ([]byte, error) {
+	if _, ok := selfTradePreventionNames[s]; !ok {
+		return nil, fmt.Errorf("enums: invalid self-trade prevention mode %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *SelfTradePrevention) UnmarshalText(text []byte) error {
+	mode, err := ParseSelfTradePrevention(string(text))
+	if err != nil {
+		return err
+	}
+	*s = mode
+	return nil
+}
@@ -0,0 +1,52 @@
+package enums
+
+import "fmt"
+
+// SettlementStatus represents the state of a settlement obligation.
+type SettlementStatus int
+
+const (
+	SettlementPending SettlementStatus = iota
+	Settled
+	SettlementFailed
+)
+
+var settlementStatusNames = map[SettlementStatus]string{
+	SettlementPending: "PENDING",
+	Settled:           "SETTLED",
+	SettlementFailed:  "FAILED",
+}
+
+func (s SettlementStatus) String() string {
+	if name, ok := settlementStatusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("SettlementStatus(%d)", int(s))
+}
+
+// ParseSettlementStatus converts a textual status (e.g. "SETTLED") into a
+// SettlementStatus.
+func ParseSettlementStatus(text string) (SettlementStatus, error) {
+	for status, name := range settlementStatusNames {
+		if equalFold(name, text) {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid settlement status %q", text)
+}
+
+func (s SettlementStatus) MarshalText() ([]byte, error) {
+	if _, ok := settlementStatusNames[s]; !ok {
+		return nil, fmt.Errorf("enums: invalid settlement status %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *SettlementStatus) UnmarshalText(text []byte) error {
+	status, err := ParseSettlementStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
@@ -0,0 +1,50 @@
+package enums
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSideText(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("BUY", enums.Buy.String())
+	assert.Equal("SELL", enums.Sell.String())
+	assert.Equal(enums.Sell, enums.Buy.Opposite())
+
+	side, err := enums.ParseSide(" sell ")
+	assert.NoError(err)
+	assert.Equal(enums.Sell, side)
+
+	_, err = enums.ParseSide("hold")
+	assert.Error(err)
+
+	encoded, err := json.Marshal(map[string]enums.Side{"side": enums.Buy})
+	assert.NoError(err)
+	assert.JSONEq(`{"side":"BUY"}`, string(encoded))
+
+	var decoded struct{ Side enums.Side }
+	assert.NoError(json.Unmarshal([]byte(`{"Side":"SELL"}`), &decoded))
+	assert.Equal(enums.Sell, decoded.Side)
+}
+
+func TestOrderStatusText(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("OPEN", enums.Open.String())
+	assert.Equal("CLOSED", enums.Closed.String())
+	assert.Equal("OrderStatus(42)", enums.OrderStatus(42).String())
+
+	status, err := enums.ParseOrderStatus("closed")
+	assert.NoError(err)
+	assert.Equal(enums.Closed, status)
+
+	var decoded enums.OrderStatus
+	assert.Error(decoded.UnmarshalText([]byte("pending")))
+
+	_, err = enums.OrderStatus(42).MarshalText()
+	assert.Error(err)
+}
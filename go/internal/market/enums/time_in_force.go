@@ -0,0 +1,55 @@
+package enums
+
+import "fmt"
+
+// TimeInForce tells how long an order stays in the book.
+type TimeInForce int
+
+const (
+	// GoodTillCancel orders stay in the book until filled or cancelled.
+	GoodTillCancel TimeInForce = iota
+	// Day orders expire at the close of the trading session.
+	Day
+	// GoodTillDate orders expire at their expiry timestamp.
+	GoodTillDate
+)
+
+var timeInForceNames = map[TimeInForce]string{
+	GoodTillCancel: "GTC",
+	Day:            "DAY",
+	GoodTillDate:   "GTD",
+}
+
+func (t TimeInForce) String() string {
+	if name, ok := timeInForceNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TimeInForce(%d)", int(t))
+}
+
+// ParseTimeInForce converts a textual time in force (e.g. "DAY") into a
+// TimeInForce.
+func ParseTimeInForce(text string) (TimeInForce, error) {
+	for timeInForce, name := range timeInForceNames {
+		if equalFold(name, text) {
+			return timeInForce, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid time in force %q", text)
+}
+
+func (t TimeInForce) MarshalText() ([]byte, error) {
+	if _, ok := timeInForceNames[t]; !ok {
+		return nil, fmt.Errorf("enums: invalid time in force %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *TimeInForce) UnmarshalText(text []byte) error {
+	timeInForce, err := ParseTimeInForce(string(text))
+	if err != nil {
+		return err
+	}
+	*t = timeInForce
+	return nil
+}
@@ -0,0 +1,69 @@
+package enums
+
+import "fmt"
+
+// TradingPhase represents the session phase an asset is trading in.
+type TradingPhase int
+
+const (
+	// Continuous matches incoming orders as soon as they arrive.
+	Continuous TradingPhase = iota
+	// PreOpen accumulates orders without matching them until the opening
+	// auction uncrosses the book.
+	PreOpen
+	// PreClose accumulates orders without matching them until the closing
+	// auction uncrosses the book.
+	PreClose
+	// MarketClosed rejects incoming orders.
+	MarketClosed
+	// Halted pauses matching, accumulating orders until trading resumes with
+	// an auction.
+	Halted
+)
+
+var tradingPhaseNames = map[TradingPhase]string{
+	Continuous:   "CONTINUOUS",
+	PreOpen:      "PRE_OPEN",
+	PreClose:     "PRE_CLOSE",
+	MarketClosed: "CLOSED",
+	Halted:       "HALTED",
+}
+
+func (p TradingPhase) String() string {
+	if name, ok := tradingPhaseNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("TradingPhase(%d)", int(p))
+}
+
+// IsCall reports whether orders accumulate for an auction during the phase.
+func (p TradingPhase) IsCall() bool {
+	return p == PreOpen || p == PreClose || p == Halted
+}
+
+// ParseTradingPhase converts a textual phase (e.g. "PRE_OPEN") into a
+// TradingPhase.
+func ParseTradingPhase(text string) (TradingPhase, error) {
+	for phase, name := range tradingPhaseNames {
+		if equalFold(name, text) {
+			return phase, nil
+		}
+	}
+	return 0, fmt.Errorf("enums: invalid trading phase %q", text)
+}
+
+func (p TradingPhase) MarshalText() ([]byte, error) {
+	if _, ok := tradingPhaseNames[p]; !ok {
+		return nil, fmt.Errorf("enums: invalid trading phase %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+func (p *TradingPhase) UnmarshalText(text []byte) error {
+	phase, err := ParseTradingPhase(string(text))
+	if err != nil {
+		return err
+	}
+	*p = phase
+	return nil
+}
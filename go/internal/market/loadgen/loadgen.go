@@ -0,0 +1,238 @@
+// Package loadgen generates synthetic order flow for the matching engine and
+// measures how long the book takes to handle it.
+package loadgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// Config describes the distributions of a synthetic order flow.
+type Config struct {
+	// Assets and Investors are how many of each the flow is spread over.
+	Assets    int
+	Investors int
+	// AssetSkew is the exponent of the Zipf distribution assets are picked
+	// from, so a few assets get most of the flow. Values up to 1 pick assets
+	// uniformly.
+	AssetSkew float64
+	// CancelRatio is the share of actions cancelling a previous order
+	// instead of sending a new one.
+	CancelRatio float64
+	// MidPrice is the price orders are centered on, and PriceStdDev the
+	// standard deviation of their prices around it, in ticks of TickSize.
+	MidPrice    float64
+	TickSize    float64
+	PriceStdDev float64
+	// MaxShares is the largest order size, sizes being uniform from 1.
+	MaxShares int
+	Seed      int64
+}
+
+// DefaultConfig returns a flow of a handful of assets with a third of the
+// actions being cancellations.
+func DefaultConfig() Config {
+	return Config{
+		Assets:      10,
+		Investors:   100,
+		CancelRatio: 0.3,
+		MidPrice:    100,
+		TickSize:    0.01,
+		PriceStdDev: 10,
+		MaxShares:   100,
+		Seed:        1,
+	}
+}
+
+// Action is a step of the order flow: either a new order to process or a
+// previous order to cancel.
+type Action struct {
+	Order  *entity.Order
+	Cancel bool
+}
+
+// maxCancellable bounds the orders the generator remembers for cancelling.
+const maxCancellable = 100000
+
+// Generator produces the actions of an order flow. The same config always
+// produces the same flow.
+type Generator struct {
+	config      Config
+	rand        *rand.Rand
+	zipf        *rand.Zipf
+	assets      []*entity.Asset
+	investors   []*entity.Investor
+	cancellable []*entity.Order
+	orders      int
+}
+
+// Validate reports the first setting of the config a generator can't draw
+// orders from.
+func (c Config) Validate() error {
+	switch {
+	case c.Assets <= 0:
+		return fmt.Errorf("loadgen: assets must be positive, got %d", c.Assets)
+	case c.Investors <= 0:
+		return fmt.Errorf("loadgen: investors must be positive, got %d", c.Investors)
+	case c.MaxShares <= 0:
+		return fmt.Errorf("loadgen: max shares must be positive, got %d", c.MaxShares)
+	}
+	return nil
+}
+
+// NewGenerator creates a generator of the flow described by config, failing
+// when the config doesn't validate.
+func NewGenerator(config Config) (*Generator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{
+		config: config,
+		rand:   rand.New(rand.NewSource(config.Seed)),
+	}
+
+	for i := 0; i < config.Assets; i++ {
+		id := fmt.Sprintf("ASSET%d", i+1)
+		g.assets = append(g.assets, entity.NewAsset(id, id, 0))
+	}
+	for i := 0; i < config.Investors; i++ {
+		g.investors = append(g.investors, entity.NewInvestor(fmt.Sprintf("INVESTOR%d", i+1)))
+	}
+	if config.AssetSkew > 1 && config.Assets > 1 {
+		g.zipf = rand.NewZipf(g.rand, config.AssetSkew, 1, uint64(config.Assets-1))
+	}
+	return g, nil
+}
+
+// Assets returns the assets of the flow.
+func (g *Generator) Assets() []*entity.Asset {
+	return g.assets
+}
+
+// Next returns the next action of the flow.
+func (g *Generator) Next() Action {
+	if len(g.cancellable) > 0 && g.rand.Float64() < g.config.CancelRatio {
+		return Action{Order: g.takeCancellable(), Cancel: true}
+	}
+
+	order := g.NewOrder()
+	g.cancellable = append(g.cancellable, order)
+	if len(g.cancellable) > maxCancellable {
+		g.takeCancellable()
+	}
+	return Action{Order: order}
+}
+
+// NewOrder returns a new order of the flow, without remembering it for
+// cancellation.
+func (g *Generator) NewOrder() *entity.Order {
+	g.orders++
+
+	side := enums.Buy
+	if g.rand.Intn(2) == 1 {
+		side = enums.Sell
+	}
+
+	ticks := math.Round(g.rand.NormFloat64() * g.config.PriceStdDev)
+	price := math.Max(g.config.MidPrice+ticks*g.config.TickSize, g.config.TickSize)
+	shares := 1 + g.rand.Intn(g.config.MaxShares)
+
+	return entity.NewOrder(fmt.Sprintf("ORDER%d", g.orders), g.investor(), g.asset(), shares, price, side)
+}
+
+func (g *Generator) asset() *entity.Asset {
+	if g.zipf != nil {
+		return g.assets[g.zipf.Uint64()]
+	}
+	return g.assets[g.rand.Intn(len(g.assets))]
+}
+
+func (g *Generator) investor() *entity.Investor {
+	return g.investors[g.rand.Intn(len(g.investors))]
+}
+
+// takeCancellable forgets a random remembered order and returns it.
+func (g *Generator) takeCancellable() *entity.Order {
+	i := g.rand.Intn(len(g.cancellable))
+	order := g.cancellable[i]
+	last := len(g.cancellable) - 1
+	g.cancellable[i] = g.cancellable[last]
+	g.cancellable[last] = nil
+	g.cancellable = g.cancellable[:last]
+	return order
+}
+
+// Apply hands an action to the book.
+func Apply(book *entity.Book, action Action) {
+	if action.Cancel {
+		book.CancelOrder(action.Order)
+		return
+	}
+	book.Process(action.Order)
+}
+
+// LatencyReport summarizes the latencies measured for a run.
+type LatencyReport struct {
+	Count int
+	Mean  time.Duration
+	Min   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	Max   time.Duration
+}
+
+// NewLatencyReport summarizes samples, sorting them in place.
+func NewLatencyReport(samples []time.Duration) LatencyReport {
+	if len(samples) == 0 {
+		return LatencyReport{}
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+
+	return LatencyReport{
+		Count: len(samples),
+		Mean:  total / time.Duration(len(samples)),
+		Min:   samples[0],
+		P50:   percentile(samples, 0.5),
+		P90:   percentile(samples, 0.9),
+		P99:   percentile(samples, 0.99),
+		P999:  percentile(samples, 0.999),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples.
+func percentile(samples []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(samples)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return samples[rank]
+}
+
+// Run applies actions of the generator to the book, measuring the latency
+// of each of them. The book's output channel must be drained while it runs.
+func Run(book *entity.Book, generator *Generator, actions int) LatencyReport {
+	samples := make([]time.Duration, 0, actions)
+	for i := 0; i < actions; i++ {
+		action := generator.Next()
+		start := time.Now()
+		Apply(book, action)
+		samples = append(samples, time.Since(start))
+	}
+	return NewLatencyReport(samples)
+}
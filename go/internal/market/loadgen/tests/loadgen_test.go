@@ -0,0 +1,74 @@
+package loadgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/loadgen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	config := loadgen.DefaultConfig()
+	config.AssetSkew = 1.5
+	generator, err := loadgen.NewGenerator(config)
+	assert.NoError(t, err)
+	other, err := loadgen.NewGenerator(config)
+	assert.NoError(t, err)
+
+	assert := assert.New(t)
+
+	cancels := 0
+	for i := 0; i < 1000; i++ {
+		action, otherAction := generator.Next(), other.Next()
+		assert.Equal(action.Cancel, otherAction.Cancel)
+		assert.Equal(action.Order.ID, otherAction.Order.ID)
+		assert.Equal(action.Order.Price, otherAction.Order.Price)
+		assert.Equal(action.Order.Shares, otherAction.Order.Shares)
+		assert.Equal(action.Order.Asset.ID, otherAction.Order.Asset.ID)
+
+		assert.GreaterOrEqual(action.Order.Shares, 1)
+		assert.LessOrEqual(action.Order.Shares, config.MaxShares)
+		if action.Cancel {
+			cancels++
+		}
+	}
+	assert.InDelta(300, cancels, 60, "Cancellations should follow the cancel ratio")
+}
+
+func TestGeneratorRejectsEmptyConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, empty := range []func(config *loadgen.Config){
+		func(config *loadgen.Config) { config.Assets = 0 },
+		func(config *loadgen.Config) { config.Investors = 0 },
+		func(config *loadgen.Config) { config.MaxShares = 0 },
+	} {
+		config := loadgen.DefaultConfig()
+		empty(&config)
+		generator, err := loadgen.NewGenerator(config)
+		assert.Error(err, "Config with nothing to draw from should be rejected")
+		assert.Nil(generator)
+	}
+}
+
+func TestLatencyReport(t *testing.T) {
+	samples := []time.Duration{}
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Microsecond)
+	}
+
+	report := loadgen.NewLatencyReport(samples)
+
+	assert := assert.New(t)
+
+	assert.Equal(100, report.Count)
+	assert.Equal(time.Microsecond, report.Min)
+	assert.Equal(100*time.Microsecond, report.Max)
+	assert.Equal(50*time.Microsecond, report.P50)
+	assert.Equal(90*time.Microsecond, report.P90)
+	assert.Equal(99*time.Microsecond, report.P99)
+	assert.Equal(100*time.Microsecond, report.P999)
+	assert.Equal(50500*time.Nanosecond, report.Mean)
+	assert.Equal(loadgen.LatencyReport{}, loadgen.NewLatencyReport(nil))
+}
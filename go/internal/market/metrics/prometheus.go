@@ -0,0 +1,141 @@
+// Package metrics exposes the measurements of the matching engine to
+// Prometheus.
+package metrics
+
+import (
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "matching_engine"
+
+// Prometheus is an entity.Metrics recording the flow of orders and trades
+// as Prometheus metrics.
+type Prometheus struct {
+	ordersReceived *prometheus.CounterVec
+	rejections     *prometheus.CounterVec
+	matches        *prometheus.CounterVec
+	tradedVolume   *prometheus.CounterVec
+	tradedNotional *prometheus.CounterVec
+	matchLatency   prometheus.Histogram
+}
+
+// NewPrometheus creates the metrics of the order flow and registers them
+// with registerer.
+func NewPrometheus(registerer prometheus.Registerer) *Prometheus {
+	p := &Prometheus{
+		ordersReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_received_total",
+			Help:      "Orders received by the book.",
+		}, []string{"asset", "side"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "order_rejections_total",
+			Help:      "Orders rejected by the book, by reason.",
+		}, []string{"reason"}),
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "matches_total",
+			Help:      "Transactions executed by the book.",
+		}, []string{"asset"}),
+		tradedVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "traded_shares_total",
+			Help:      "Shares traded by the book.",
+		}, []string{"asset"}),
+		tradedNotional: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "traded_notional_total",
+			Help:      "Value traded by the book.",
+		}, []string{"asset"}),
+		matchLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "match_latency_seconds",
+			Help:      "Time taken by the book to process an incoming order.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}),
+	}
+
+	registerer.MustRegister(p.ordersReceived, p.rejections, p.matches, p.tradedVolume, p.tradedNotional, p.matchLatency)
+	return p
+}
+
+func (p *Prometheus) OrderReceived(order *entity.Order) {
+	p.ordersReceived.WithLabelValues(order.Asset.ID, order.OrderType.String()).Inc()
+}
+
+func (p *Prometheus) OrderProcessed(order *entity.Order, latency time.Duration) {
+	p.matchLatency.Observe(latency.Seconds())
+}
+
+func (p *Prometheus) OrderRejected(order *entity.Order) {
+	reason := "unknown"
+	if order.RejectReason != nil {
+		reason = order.RejectReason.Error()
+	}
+	p.rejections.WithLabelValues(reason).Inc()
+}
+
+func (p *Prometheus) TransactionExecuted(t *entity.Transaction) {
+	assetID := t.SellingOrder.Asset.ID
+	p.matches.WithLabelValues(assetID).Inc()
+	p.tradedVolume.WithLabelValues(assetID).Add(float64(t.Shares))
+	p.tradedNotional.WithLabelValues(assetID).Add(t.Total)
+}
+
+// BookCollector reports the state of a book when scraped: the orders and
+// shares resting on each side of every asset, and how many orders wait in
+// the book's input and output channels.
+type BookCollector struct {
+	book *entity.Book
+
+	restingOrders  *prometheus.Desc
+	restingShares  *prometheus.Desc
+	ordersInQueue  *prometheus.Desc
+	ordersOutQueue *prometheus.Desc
+}
+
+func NewBookCollector(book *entity.Book) *BookCollector {
+	return &BookCollector{
+		book: book,
+		restingOrders: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "resting_orders"),
+			"Orders resting in the book.",
+			[]string{"asset", "side"}, nil,
+		),
+		restingShares: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "resting_shares"),
+			"Pending shares of the orders resting in the book.",
+			[]string{"asset", "side"}, nil,
+		),
+		ordersInQueue: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "orders_in_queue_length"),
+			"Orders waiting in the input channel of the book.",
+			nil, nil,
+		),
+		ordersOutQueue: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "orders_out_queue_length"),
+			"Order updates waiting in the output channel of the book.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *BookCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.restingOrders
+	ch <- c.restingShares
+	ch <- c.ordersInQueue
+	ch <- c.ordersOutQueue
+}
+
+func (c *BookCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, totals := range c.book.RestingTotals() {
+		ch <- prometheus.MustNewConstMetric(c.restingOrders, prometheus.GaugeValue, float64(totals.Orders), totals.AssetID, totals.Side.String())
+		ch <- prometheus.MustNewConstMetric(c.restingShares, prometheus.GaugeValue, float64(totals.Shares), totals.AssetID, totals.Side.String())
+	}
+	ch <- prometheus.MustNewConstMetric(c.ordersInQueue, prometheus.GaugeValue, float64(len(c.book.OrdersChanIn)))
+	ch <- prometheus.MustNewConstMetric(c.ordersOutQueue, prometheus.GaugeValue, float64(len(c.book.OrderChanOut)))
+}
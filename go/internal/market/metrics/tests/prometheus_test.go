@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/medina325/stock_market/go/internal/market/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	a := entity.NewAsset("PETR4", "Asset 1", 100)
+	sellInvestor := entity.NewInvestor(uuid.NewString())
+	buyInvestor := entity.NewInvestor(uuid.NewString())
+
+	ordersIn := make(chan *entity.Order, 10)
+	ordersOut := make(chan *entity.Order, 10)
+	book := entity.NewBook(ordersIn, ordersOut, nil)
+
+	registry := prometheus.NewRegistry()
+	book.Metrics = metrics.NewPrometheus(registry)
+	registry.MustRegister(metrics.NewBookCollector(book))
+
+	postOnlyOrder := entity.NewOrder(uuid.NewString(), buyInvestor, a, 1, 10, enums.Buy)
+	postOnlyOrder.PostOnly = true
+
+	book.Process(entity.NewOrder(uuid.NewString(), sellInvestor, a, 10, 10, enums.Sell))
+	book.Process(entity.NewOrder(uuid.NewString(), buyInvestor, a, 4, 10, enums.Buy))
+	book.Process(postOnlyOrder)
+	ordersIn <- entity.NewOrder(uuid.NewString(), buyInvestor, a, 1, 9, enums.Buy)
+
+	expected := `
+# HELP matching_engine_matches_total Transactions executed by the book.
+# TYPE matching_engine_matches_total counter
+matching_engine_matches_total{asset="PETR4"} 1
+# HELP matching_engine_order_rejections_total Orders rejected by the book, by reason.
+# TYPE matching_engine_order_rejections_total counter
+matching_engine_order_rejections_total{reason="post-only order would take liquidity"} 1
+# HELP matching_engine_orders_in_queue_length Orders waiting in the input channel of the book.
+# TYPE matching_engine_orders_in_queue_length gauge
+matching_engine_orders_in_queue_length 1
+# HELP matching_engine_orders_out_queue_length Order updates waiting in the output channel of the book.
+# TYPE matching_engine_orders_out_queue_length gauge
+matching_engine_orders_out_queue_length 3
+# HELP matching_engine_orders_received_total Orders received by the book.
+# TYPE matching_engine_orders_received_total counter
+matching_engine_orders_received_total{asset="PETR4",side="BUY"} 2
+matching_engine_orders_received_total{asset="PETR4",side="SELL"} 1
+# HELP matching_engine_resting_orders Orders resting in the book.
+# TYPE matching_engine_resting_orders gauge
+matching_engine_resting_orders{asset="PETR4",side="BUY"} 0
+matching_engine_resting_orders{asset="PETR4",side="SELL"} 1
+# HELP matching_engine_resting_shares Pending shares of the orders resting in the book.
+# TYPE matching_engine_resting_shares gauge
+matching_engine_resting_shares{asset="PETR4",side="BUY"} 0
+matching_engine_resting_shares{asset="PETR4",side="SELL"} 6
+# HELP matching_engine_traded_notional_total Value traded by the book.
+# TYPE matching_engine_traded_notional_total counter
+matching_engine_traded_notional_total{asset="PETR4"} 40
+# HELP matching_engine_traded_shares_total Shares traded by the book.
+# TYPE matching_engine_traded_shares_total counter
+matching_engine_traded_shares_total{asset="PETR4"} 4
+`
+
+	assert := assert.New(t)
+
+	assert.NoError(testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"matching_engine_matches_total",
+		"matching_engine_order_rejections_total",
+		"matching_engine_orders_in_queue_length",
+		"matching_engine_orders_out_queue_length",
+		"matching_engine_orders_received_total",
+		"matching_engine_resting_orders",
+		"matching_engine_resting_shares",
+		"matching_engine_traded_notional_total",
+		"matching_engine_traded_shares_total",
+	))
+	assert.Equal(1, testutil.CollectAndCount(registry, "matching_engine_match_latency_seconds"))
+}
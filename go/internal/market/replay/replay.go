@@ -0,0 +1,240 @@
+// Package replay runs a recorded stream of orders through a Book on a
+// simulated clock, so the same input always produces the same trades.
+package replay
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/entity"
+	"github.com/medina325/stock_market/go/internal/market/enums"
+)
+
+// OrderRecord is an order of the replayed stream, read as a JSON line. The
+// clock of the book is moved to Time before the order is processed.
+type OrderRecord struct {
+	Time          time.Time         `json:"time"`
+	ID            string            `json:"id"`
+	InvestorID    string            `json:"investor_id"`
+	AssetID       string            `json:"asset_id"`
+	Side          enums.Side        `json:"side"`
+	Shares        int               `json:"shares"`
+	Price         float64           `json:"price"`
+	TimeInForce   enums.TimeInForce `json:"time_in_force"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	DisplayShares int               `json:"display_shares"`
+	PostOnly      bool              `json:"post_only"`
+	Hidden        bool              `json:"hidden"`
+	TraceID       string            `json:"trace_id"`
+}
+
+// Order creates the order of a record, placed by investor for asset.
+func (record OrderRecord) Order(investor *entity.Investor, asset *entity.Asset) *entity.Order {
+	order := entity.NewOrder(record.ID, investor, asset, record.Shares, record.Price, record.Side)
+	order.TimeInForce = record.TimeInForce
+	order.ExpiresAt = record.ExpiresAt
+	if !record.ExpiresAt.IsZero() {
+		order.TimeInForce = enums.GoodTillDate
+	}
+	order.DisplayShares = record.DisplayShares
+	order.PostOnly = record.PostOnly
+	order.Hidden = record.Hidden
+	order.TraceID = record.TraceID
+	return order
+}
+
+// TradeRecord is a transaction executed by the replay.
+type TradeRecord struct {
+	ID          string     `json:"id"`
+	DateTime    time.Time  `json:"date_time"`
+	AssetID     string     `json:"asset_id"`
+	Price       float64    `json:"price"`
+	Shares      int        `json:"shares"`
+	Aggressor   enums.Side `json:"aggressor"`
+	BuyOrderID  string     `json:"buy_order_id"`
+	SellOrderID string     `json:"sell_order_id"`
+	BuyerID     string     `json:"buyer_id"`
+	SellerID    string     `json:"seller_id"`
+	BuyerFee    float64    `json:"buyer_fee"`
+	SellerFee   float64    `json:"seller_fee"`
+	TraceID     string     `json:"trace_id,omitempty"`
+}
+
+// RestingOrderRecord is an order left in the book at the end of the replay.
+type RestingOrderRecord struct {
+	ID            string  `json:"id"`
+	InvestorID    string  `json:"investor_id"`
+	Price         float64 `json:"price"`
+	Shares        int     `json:"shares"`
+	PendingShares int     `json:"pending_shares"`
+	Hidden        bool    `json:"hidden,omitempty"`
+}
+
+// AssetState is the book of an asset at the end of the replay. Bids and asks
+// are listed in the priority order they would be matched in.
+type AssetState struct {
+	AssetID   string               `json:"asset_id"`
+	Phase     enums.TradingPhase   `json:"phase"`
+	LastPrice float64              `json:"last_price"`
+	Bids      []RestingOrderRecord `json:"bids"`
+	Asks      []RestingOrderRecord `json:"asks"`
+}
+
+// BookState is the state of every asset of the book, sorted by asset ID.
+type BookState struct {
+	Assets []AssetState `json:"assets"`
+}
+
+// Replayer feeds orders to a book driven by a fake clock and seeded
+// transaction IDs.
+type Replayer struct {
+	Book  *entity.Book
+	Clock *entity.FakeClock
+
+	orderChanOut chan *entity.Order
+	investors    map[string]*entity.Investor
+	assets       map[string]*entity.Asset
+}
+
+// NewReplayer creates a replayer whose clock starts at start and whose
+// transaction IDs are generated from seed. The book can be configured
+// before the first order is replayed.
+func NewReplayer(seed int64, start time.Time) *Replayer {
+	orderChanOut := make(chan *entity.Order)
+	clock := entity.NewFakeClock(start)
+
+	book := entity.NewBook(nil, orderChanOut, nil)
+	book.Clock = clock
+	book.IDGenerator = entity.UUIDGenerator{Rand: rand.New(rand.NewSource(seed))}
+
+	go func() {
+		for range orderChanOut {
+		}
+	}()
+
+	return &Replayer{
+		Book:         book,
+		Clock:        clock,
+		orderChanOut: orderChanOut,
+		investors:    make(map[string]*entity.Investor),
+		assets:       make(map[string]*entity.Asset),
+	}
+}
+
+// Close releases the replayer once it is no longer used.
+func (r *Replayer) Close() {
+	close(r.orderChanOut)
+}
+
+// Run replays every order of a JSON lines stream.
+func (r *Replayer) Run(orders io.Reader) error {
+	decoder := json.NewDecoder(orders)
+	for {
+		var record OrderRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r.Replay(record)
+	}
+}
+
+// Replay moves the clock to the time of an order, letting the book act on
+// the time passed, and processes the order.
+func (r *Replayer) Replay(record OrderRecord) {
+	if record.Time.After(r.Clock.Now()) {
+		r.Clock.Set(record.Time)
+		r.Book.Tick()
+	}
+
+	r.Book.Process(record.Order(r.investor(record.InvestorID), r.asset(record.AssetID)))
+}
+
+func (r *Replayer) investor(investorID string) *entity.Investor {
+	if r.investors[investorID] == nil {
+		r.investors[investorID] = entity.NewInvestor(investorID)
+	}
+	return r.investors[investorID]
+}
+
+func (r *Replayer) asset(assetID string) *entity.Asset {
+	if r.assets[assetID] == nil {
+		r.assets[assetID] = entity.NewAsset(assetID, assetID, 0)
+	}
+	return r.assets[assetID]
+}
+
+// Trades returns the transactions executed so far, in execution order.
+func (r *Replayer) Trades() []TradeRecord {
+	trades := make([]TradeRecord, 0, len(r.Book.Transactions))
+	for _, t := range r.Book.Transactions {
+		trades = append(trades, TradeRecord{
+			ID:          t.ID(),
+			DateTime:    t.DateTime,
+			AssetID:     t.SellingOrder.Asset.ID,
+			Price:       t.Price,
+			Shares:      t.Shares,
+			Aggressor:   t.Aggressor,
+			BuyOrderID:  t.BuyingOrder.ID,
+			SellOrderID: t.SellingOrder.ID,
+			BuyerID:     t.BuyingOrder.Investor.ID,
+			SellerID:    t.SellingOrder.Investor.ID,
+			BuyerFee:    t.BuyerFee,
+			SellerFee:   t.SellerFee,
+			TraceID:     t.TraceID,
+		})
+	}
+	return trades
+}
+
+// State returns what is left in the book.
+func (r *Replayer) State() BookState {
+	state := BookState{Assets: []AssetState{}}
+	for _, assetID := range r.Book.AssetIDs() {
+		lastPrice, _ := r.Book.LastPrice(assetID)
+		state.Assets = append(state.Assets, AssetState{
+			AssetID:   assetID,
+			Phase:     r.Book.Phase(assetID),
+			LastPrice: lastPrice,
+			Bids:      restingOrderRecords(r.Book.RestingOrders(assetID, enums.Buy)),
+			Asks:      restingOrderRecords(r.Book.RestingOrders(assetID, enums.Sell)),
+		})
+	}
+	return state
+}
+
+func restingOrderRecords(orders []*entity.Order) []RestingOrderRecord {
+	records := make([]RestingOrderRecord, 0, len(orders))
+	for _, order := range orders {
+		records = append(records, RestingOrderRecord{
+			ID:            order.ID,
+			InvestorID:    order.Investor.ID,
+			Price:         order.Price,
+			Shares:        order.Shares,
+			PendingShares: order.PendingShares,
+			Hidden:        order.Hidden,
+		})
+	}
+	return records
+}
+
+// WriteTrades writes the executed transactions as JSON lines.
+func (r *Replayer) WriteTrades(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, trade := range r.Trades() {
+		if err := encoder.Encode(trade); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteState writes what is left in the book as indented JSON.
+func (r *Replayer) WriteState(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.State())
+}
@@ -0,0 +1,59 @@
+package replay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medina325/stock_market/go/internal/market/enums"
+	"github.com/medina325/stock_market/go/internal/market/replay"
+	"github.com/stretchr/testify/assert"
+)
+
+const orders = `{"time":"2023-09-08T10:00:00Z","id":"s1","investor_id":"alice","asset_id":"PETR4","side":"SELL","shares":10,"price":10}
+{"time":"2023-09-08T10:00:01Z","id":"s2","investor_id":"carol","asset_id":"PETR4","side":"SELL","shares":5,"price":10.5}
+{"time":"2023-09-08T10:00:02Z","id":"b1","investor_id":"bob","asset_id":"PETR4","side":"BUY","shares":12,"price":10.5}
+{"time":"2023-09-08T10:00:03Z","id":"b2","investor_id":"bob","asset_id":"VALE3","side":"BUY","shares":3,"price":60,"expires_at":"2023-09-08T10:30:00Z"}
+{"time":"2023-09-08T11:00:00Z","id":"b3","investor_id":"dave","asset_id":"PETR4","side":"BUY","shares":1,"price":9}
+`
+
+func runReplay(t *testing.T) (*replay.Replayer, string, string) {
+	replayer := replay.NewReplayer(42, time.Date(2023, 9, 8, 9, 0, 0, 0, time.UTC))
+	t.Cleanup(replayer.Close)
+
+	var trades, state bytes.Buffer
+	assert.NoError(t, replayer.Run(strings.NewReader(orders)))
+	assert.NoError(t, replayer.WriteTrades(&trades))
+	assert.NoError(t, replayer.WriteState(&state))
+	return replayer, trades.String(), state.String()
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	replayer, trades, state := runReplay(t)
+	_, otherTrades, otherState := runReplay(t)
+
+	assert := assert.New(t)
+
+	assert.Equal(trades, otherTrades, "Replays should write the same trades")
+	assert.Equal(state, otherState, "Replays should write the same book state")
+
+	records := replayer.Trades()
+	assert.Len(records, 2)
+	assert.Equal("s1", records[0].SellOrderID)
+	assert.Equal(10, records[0].Shares)
+	assert.Equal(time.Date(2023, 9, 8, 10, 0, 2, 0, time.UTC), records[0].DateTime, "Trades should be dated by the simulated clock")
+	assert.Equal(10.5, records[1].Price)
+	assert.Equal(enums.Buy, records[1].Aggressor)
+
+	bookState := replayer.State()
+	assert.Len(bookState.Assets, 2)
+	petr4 := bookState.Assets[0]
+	assert.Equal("PETR4", petr4.AssetID)
+	assert.Equal(10.5, petr4.LastPrice)
+	assert.Len(petr4.Bids, 1)
+	assert.Equal("b3", petr4.Bids[0].ID)
+	assert.Len(petr4.Asks, 1)
+	assert.Equal(3, petr4.Asks[0].PendingShares)
+	assert.Empty(bookState.Assets[1].Bids, "Good-till-date order should expire as the clock moves past it")
+}